@@ -5,21 +5,55 @@ import (
 	"net/http"
 
 	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/token"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type accountResponse struct {
+	ID             int64              `json:"id"`
+	Owner          string             `json:"owner"`
+	Balance        money.Amount       `json:"balance"`
+	Currency       string             `json:"currency"`
+	OverdraftLimit money.Amount       `json:"overdraft_limit"`
+	Status         string             `json:"status"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID:             account.ID,
+		Owner:          account.Owner,
+		Balance:        money.New(account.Balance, account.Currency),
+		Currency:       account.Currency,
+		OverdraftLimit: money.New(account.OverdraftLimit, account.Currency),
+		Status:         account.Status,
+		CreatedAt:      account.CreatedAt,
+	}
+}
+
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
-	Owner    string `json:"owner" binding:"required"`
 }
 
 type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+// listAccountRequest pages by cursor unless PageID is set.
+// Offset paging through PageID is kept for existing clients
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	PageID   int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Cursor   string `form:"cursor" binding:"excluded_with=PageID"`
+}
+
+type listAccountResponse struct {
+	Accounts   []accountResponse `json:"accounts"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -29,19 +63,34 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.CreateAccountParams{
-		Balance:  0,
-		Currency: req.Currency,
-		Owner:    req.Owner,
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, req, http.StatusCreated, func(result interface{}) interface{} {
+		return newAccountResponse(result.(db.Account))
+	})
+	if !ok {
+		return
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, err := server.service.CreateAccount(ctx, service.CreateAccountParams{
+		Owner:          authPayload.Username,
+		Currency:       req.Currency,
+		IdempotencyKey: idempotencyKey,
+	})
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			server.idempotencyKeyInUse(ctx, idempotencyKey)
+			return
+		}
+		errCode := db.ErrorCode(err)
+		if errCode == db.ForeignKeyViolation || errCode == db.UniqueViolation {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	ctx.JSON(http.StatusCreated, newAccountResponse(account))
 }
 
 func (server *Server) getAccount(ctx *gin.Context) {
@@ -51,17 +100,35 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	account, ok := server.authorizedAccount(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// authorizedAccount loads the account and checks that it belongs to the authenticated user.
+// It writes the error response and returns false if it doesn't
+func (server *Server) authorizedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			return account, false
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return account, false
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
 }
 
 func (server *Server) listAccount(ctx *gin.Context) {
@@ -71,7 +138,14 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.PageID == 0 {
+		server.listAccountAfter(ctx, authPayload.Username, req)
+		return
+	}
+
 	arg := db.ListAccountsParams{
+		Owner:  authPayload.Username,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -82,5 +156,48 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	resp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		resp[i] = newAccountResponse(account)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// listAccountAfter returns the page of accounts following req.Cursor in (created_at, id) order
+func (server *Server) listAccountAfter(ctx *gin.Context, owner string, req listAccountRequest) {
+	scope := pagination.AccountsScope(owner)
+	after, err := server.decodeCursor(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// fetch one extra row to find out whether there is another page
+	arg := db.ListAccountsAfterParams{
+		Owner:          owner,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		RowLimit:       req.PageSize + 1,
+	}
+
+	accounts, err := server.store.ListAccountsAfter(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	accounts, next := pagination.Page(accounts, req.PageSize, func(account db.Account) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: account.CreatedAt.Time, ID: account.ID}
+	})
+
+	resp := listAccountResponse{
+		Accounts:   make([]accountResponse, len(accounts)),
+		NextCursor: server.encodeCursor(scope, next),
+	}
+	for i, account := range accounts {
+		resp.Accounts[i] = newAccountResponse(account)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// closeAccountRequest names the account that receives the remaining balance.
+// The body can be left out if the balance is already zero
+type closeAccountRequest struct {
+	SweepAccountID int64 `json:"sweep_account_id" binding:"omitempty,min=1"`
+}
+
+type closeAccountResponse struct {
+	Account accountResponse `json:"account"`
+	// Sweep is the transfer of the remaining balance, if there was one
+	Sweep *transferTxResponse `json:"sweep"`
+}
+
+// changeAccountStatus returns a handler that moves any account to the status.
+// Freezing is a compliance action, so the route must only be open to bankers
+func (server *Server) changeAccountStatus(status string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req getAccountRequest
+		if err := ctx.ShouldBindUri(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+			AccountID: req.ID,
+			Status:    status,
+		})
+		if err != nil {
+			accountStatusChangeError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newAccountResponse(result.Account))
+	}
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.SweepAccountID == uri.ID {
+		err := errors.New("cannot sweep the balance into the account being closed")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.authorizedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if req.SweepAccountID != 0 {
+		if _, valid := server.validAccount(ctx, req.SweepAccountID, account.Currency); !valid {
+			return
+		}
+	}
+
+	result, err := server.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID:      account.ID,
+		Status:         db.AccountStatusClosed,
+		SweepAccountID: req.SweepAccountID,
+	})
+	if err != nil {
+		accountStatusChangeError(ctx, err)
+		return
+	}
+
+	resp := closeAccountResponse{
+		Account: newAccountResponse(result.Account),
+	}
+	if result.Sweep != nil {
+		sweep := newTransferTxResponse(*result.Sweep)
+		resp.Sweep = &sweep
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// accountStatusChangeError writes the response for an error from ChangeAccountStatusTx
+func accountStatusChangeError(ctx *gin.Context, err error) {
+	var accountStatusErr *db.AccountStatusError
+
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrInvalidStatusChange):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrAccountNotEmpty), errors.As(err, &accountStatusErr):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(fmt.Errorf("cannot close account: %w", err)))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type updateCurrencyURI struct {
+	Code string `uri:"code" binding:"required,len=3"`
+}
+
+type updateCurrencyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+func (server *Server) updateCurrency(ctx *gin.Context) {
+	var uri updateCurrencyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	currency, err := server.store.UpdateCurrencyEnabled(ctx, db.UpdateCurrencyEnabledParams{
+		Code:    uri.Code,
+		Enabled: *req.Enabled,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.currencies.Put(currency)
+	ctx.JSON(http.StatusOK, currency)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCurrencyAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+	depositor, _ := randomUser(t)
+
+	aud := db.Currency{
+		Code:        "AUD",
+		Exponent:    2,
+		Enabled:     true,
+		DisplayName: "Australian Dollar",
+	}
+	pln := db.Currency{
+		Code:        "PLN",
+		Exponent:    2,
+		Enabled:     true,
+		DisplayName: "Polish Zloty",
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			code: aud.Code,
+			body: gin.H{"enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCurrencyEnabledParams{
+					Code:    aud.Code,
+					Enabled: true,
+				}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(arg)).Times(1).Return(aud, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				// the registry serves the new currency straight away
+				assert.True(t, server.currencies.IsSupported(aud.Code))
+			},
+		},
+		{
+			name: "NotBanker",
+			code: aud.Code,
+			body: gin.H{"enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, depositor.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(depositor.Username)).Times(1).Return(depositor, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.False(t, server.currencies.IsSupported(aud.Code))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			code: aud.Code,
+			body: gin.H{"enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			code: "CHF",
+			body: gin.H{"enabled": false},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotInISOTable",
+			code: pln.Code,
+			body: gin.H{"enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCurrencyEnabledParams{
+					Code:    pln.Code,
+					Enabled: true,
+				}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(arg)).Times(1).Return(pln, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				// the exponent comes from the currencies table, not the money package
+				exponent, ok := server.currencies.Exponent(pln.Code)
+				assert.True(t, ok)
+				assert.Equal(t, 2, exponent)
+			},
+		},
+		{
+			name: "MissingEnabled",
+			code: aud.Code,
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			code: aud.Code,
+			body: gin.H{"enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/currencies/%s", tc.code)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
+
+func TestListCurrenciesAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	currencies := []db.Currency{
+		{Code: "AUD", Exponent: 2, Enabled: false, DisplayName: "Australian Dollar"},
+		{Code: util.GBP, Exponent: 2, Enabled: true, DisplayName: "Pound Sterling"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(currencies, nil)
+
+	server := newTestServer(t, store, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/currencies", nil)
+	assert.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotCurrencies []db.Currency
+	err = json.Unmarshal(recorder.Body.Bytes(), &gotCurrencies)
+	assert.NoError(t, err)
+	assert.Equal(t, currencies, gotCurrencies)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type getEntryRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listEntriesRequest pages through an account's entries, optionally within [From, To)
+type listEntriesRequest struct {
+	From     time.Time `form:"from"`
+	To       time.Time `form:"to" binding:"omitempty,gtfield=From"`
+	PageSize int32     `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor   string    `form:"cursor"`
+}
+
+// entryDetailResponse is an entry with the transfer that produced it, if any
+type entryDetailResponse struct {
+	entryResponse
+	Transfer *transferResponse `json:"transfer"`
+}
+
+type listEntriesResponse struct {
+	Entries    []entryDetailResponse `json:"entries"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// newEntryDetailResponse builds the response from the entry columns and the left joined transfer columns,
+// which are all null when the entry did not come from a transfer
+func newEntryDetailResponse(row db.ListAccountEntriesRow, currency string) entryDetailResponse {
+	resp := entryDetailResponse{
+		entryResponse: newEntryResponse(row.Entry, currency),
+	}
+
+	if row.Entry.TransferID.Valid {
+		transfer := newTransferResponse(db.Transfer{
+			ID:            row.Entry.TransferID.Int64,
+			FromAccountID: row.TransferFromAccountID.Int64,
+			ToAccountID:   row.TransferToAccountID.Int64,
+			Amount:        row.TransferAmount.Int64,
+			ToAmount:      row.TransferToAmount.Int64,
+			ExchangeRate:  *row.TransferExchangeRate,
+			CreatedAt:     row.TransferCreatedAt,
+		}, row.TransferFromCurrency.String, row.TransferToCurrency.String)
+		resp.Transfer = &transfer
+	}
+
+	return resp
+}
+
+func (server *Server) getEntry(ctx *gin.Context) {
+	var req getEntryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entry, err := server.store.GetEntryDetails(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	account, ok := server.authorizedAccount(ctx, entry.Entry.AccountID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newEntryDetailResponse(db.ListAccountEntriesRow(entry), account.Currency))
+}
+
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri listEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scope := pagination.EntriesScope(uri.ID)
+	after, err := server.decodeCursor(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.authorizedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	// fetch one extra row to find out whether there is another page
+	arg := db.ListAccountEntriesParams{
+		AccountID:      account.ID,
+		FromTime:       pgtype.Timestamptz{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:         pgtype.Timestamptz{Time: req.To, Valid: !req.To.IsZero()},
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		RowLimit:       req.PageSize + 1,
+	}
+
+	entries, err := server.store.ListAccountEntries(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entries, next := pagination.Page(entries, req.PageSize, func(row db.ListAccountEntriesRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.Entry.CreatedAt.Time, ID: row.Entry.ID}
+	})
+
+	resp := listEntriesResponse{
+		Entries:    make([]entryDetailResponse, len(entries)),
+		NextCursor: server.encodeCursor(scope, next),
+	}
+	for i, row := range entries {
+		resp.Entries[i] = newEntryDetailResponse(row, account.Currency)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
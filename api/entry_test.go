@@ -0,0 +1,406 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEntryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "GBP"
+
+	entry := randomEntryDetails(t, account, util.RandomInt(1, 1000))
+	plainEntry := randomEntryDetails(t, account, 0)
+
+	testCases := []struct {
+		name          string
+		entryID       int64
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			entryID:  entry.Entry.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Eq(entry.Entry.ID)).
+					Times(1).
+					Return(db.GetEntryDetailsRow(entry), nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, float64(entry.Entry.ID), got["id"])
+				assert.Equal(t, "-10.00", got["amount"])
+
+				transfer := got["transfer"].(map[string]interface{})
+				assert.Equal(t, float64(entry.Entry.TransferID.Int64), transfer["id"])
+				assert.Equal(t, "10.00", transfer["amount"])
+				assert.Equal(t, "12.50", transfer["to_amount"])
+				assert.Equal(t, "1.25", transfer["exchange_rate"])
+			},
+		},
+		{
+			name:     "NoTransfer",
+			entryID:  plainEntry.Entry.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Eq(plainEntry.Entry.ID)).
+					Times(1).
+					Return(db.GetEntryDetailsRow(plainEntry), nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Contains(t, got, "transfer")
+				assert.Nil(t, got["transfer"])
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			entryID:  entry.Entry.ID,
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Eq(entry.Entry.ID)).
+					Times(1).
+					Return(db.GetEntryDetailsRow(entry), nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			entryID:  entry.Entry.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Eq(entry.Entry.ID)).
+					Times(1).
+					Return(db.GetEntryDetailsRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			entryID:  entry.Entry.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetEntryDetailsRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidID",
+			entryID:  0,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetEntryDetails(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/entries/%d", tc.entryID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEntriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "GBP"
+
+	n := 5
+	entries := make([]db.ListAccountEntriesRow, n+1)
+	for i := range entries {
+		entries[i] = randomEntryDetails(t, account, int64(i+1))
+	}
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	after := pagination.Cursor{CreatedAt: from, ID: 42}
+
+	testCases := []struct {
+		name          string
+		query         func(server *Server) url.Values
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstPage",
+			query: func(server *Server) url.Values {
+				return url.Values{"page_size": {fmt.Sprint(n)}}
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				arg := db.ListAccountEntriesParams{
+					AccountID: account.ID,
+					RowLimit:  int32(n + 1),
+				}
+				store.EXPECT().
+					ListAccountEntries(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp struct {
+					Entries    []json.RawMessage `json:"entries"`
+					NextCursor string            `json:"next_cursor"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Len(t, resp.Entries, n)
+
+				next, err := server.cursorSigner.Decode(pagination.EntriesScope(account.ID), resp.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, entries[n-1].Entry.ID, next.ID)
+			},
+		},
+		{
+			name: "DateRangeAndCursor",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"from":      {from.Format(time.RFC3339)},
+					"to":        {to.Format(time.RFC3339)},
+					"cursor":    {server.cursorSigner.Encode(pagination.EntriesScope(account.ID), after)},
+				}
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				arg := db.ListAccountEntriesParams{
+					AccountID:      account.ID,
+					FromTime:       pgtype.Timestamptz{Time: from, Valid: true},
+					ToTime:         pgtype.Timestamptz{Time: to, Valid: true},
+					AfterCreatedAt: after.CreatedAt,
+					AfterID:        after.ID,
+					RowLimit:       int32(n + 1),
+				}
+				store.EXPECT().
+					ListAccountEntries(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(entries[:1], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				expected, err := json.Marshal(listEntriesResponse{
+					Entries: []entryDetailResponse{newEntryDetailResponse(entries[0], account.Currency)},
+				})
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(expected), recorder.Body.String())
+			},
+		},
+		{
+			name: "CursorForOtherAccount",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"cursor":    {server.cursorSigner.Encode(pagination.EntriesScope(account.ID+1), after)},
+				}
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ToBeforeFrom",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"from":      {to.Format(time.RFC3339)},
+					"to":        {from.Format(time.RFC3339)},
+				}
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			query: func(server *Server) url.Values {
+				return url.Values{"page_size": {fmt.Sprint(n)}}
+			},
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListAccountEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: func(server *Server) url.Values {
+				return url.Values{"page_size": {fmt.Sprint(n)}}
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListAccountEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListAccountEntriesRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries", account.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+			request.URL.RawQuery = tc.query(server).Encode()
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+// randomEntryDetails returns a debit of 10.00 GBP on the account.
+// With a transfer ID the entry comes from a transfer that paid 12.50 USD to another account
+func randomEntryDetails(t *testing.T, account db.Account, transferID int64) db.ListAccountEntriesRow {
+	row := db.ListAccountEntriesRow{
+		Entry: db.Entry{
+			ID:        util.RandomInt(1, 1000),
+			AccountID: account.ID,
+			Amount:    -1000,
+			CreatedAt: pgtype.Timestamptz{Time: time.Now().UTC().Truncate(time.Second), Valid: true},
+		},
+	}
+
+	if transferID != 0 {
+		row.Entry.TransferID = pgtype.Int8{Int64: transferID, Valid: true}
+		row.TransferFromAccountID = pgtype.Int8{Int64: account.ID, Valid: true}
+		row.TransferToAccountID = pgtype.Int8{Int64: util.RandomInt(1, 1000), Valid: true}
+		row.TransferAmount = pgtype.Int8{Int64: 1000, Valid: true}
+		row.TransferToAmount = pgtype.Int8{Int64: 1250, Valid: true}
+		exchangeRate := money.MustParseRate("1.25")
+		row.TransferExchangeRate = &exchangeRate
+		row.TransferCreatedAt = row.Entry.CreatedAt
+		row.TransferFromCurrency = pgtype.Text{String: account.Currency, Valid: true}
+		row.TransferToCurrency = pgtype.Text{String: "USD", Valid: true}
+	}
+
+	return row
+}
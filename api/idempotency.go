@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+// checkIdempotencyKey looks up the Idempotency-Key header of the request.
+// It returns the key to save with the result, or nil when the header is absent.
+// newResponse must build the same body the handler responds with from the transaction result.
+// If the key was already used, the original response is replayed and the handler must stop
+func (server *Server) checkIdempotencyKey(
+	ctx *gin.Context,
+	req interface{},
+	status int,
+	newResponse func(result interface{}) interface{},
+) (*db.IdempotencyKeyParams, bool) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, true
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("idempotency key must not exceed %d characters", maxIdempotencyKeyLength)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return nil, false
+	}
+
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := &db.IdempotencyKeyParams{
+		Key:            key,
+		Username:       authPayload.Username,
+		RequestPath:    ctx.FullPath(),
+		RequestHash:    requestHash,
+		ResponseStatus: int32(status),
+		ExpiresAt:      time.Now().Add(server.config.IdempotencyKeyDuration),
+		NewResponse:    newResponse,
+	}
+
+	if server.replayIdempotencyKey(ctx, arg) {
+		return nil, false
+	}
+
+	return arg, true
+}
+
+// replayIdempotencyKey writes the stored response of a previous request with the same key.
+// It returns false when no live key exists and the request should be processed
+func (server *Server) replayIdempotencyKey(ctx *gin.Context, arg *db.IdempotencyKeyParams) bool {
+	idempotencyKey, found, err := server.service.StoredResponse(ctx, arg)
+	if err != nil {
+		if errors.Is(err, service.ErrIdempotencyKeyMismatch) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if !found {
+		return false
+	}
+
+	ctx.Data(int(idempotencyKey.ResponseStatus), gin.MIMEJSON, idempotencyKey.ResponseBody)
+	return true
+}
+
+// idempotencyKeyInUse handles a key stored by a concurrent request after our lookup
+func (server *Server) idempotencyKeyInUse(ctx *gin.Context, arg *db.IdempotencyKeyParams) {
+	if server.replayIdempotencyKey(ctx, arg) {
+		return
+	}
+
+	ctx.JSON(http.StatusConflict, errorResponse(db.ErrIdempotencyKeyInUse))
+}
+
+func hashRequest(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash request: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
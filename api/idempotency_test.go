@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type eqTransferTxIdempotencyKeyMatcher struct {
+	key      string
+	username string
+}
+
+func (e eqTransferTxIdempotencyKeyMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.TransferTxParams)
+	if !ok || arg.IdempotencyKey == nil {
+		return false
+	}
+
+	idempotencyKey := arg.IdempotencyKey
+	return idempotencyKey.Key == e.key &&
+		idempotencyKey.Username == e.username &&
+		idempotencyKey.RequestPath == "/transfers" &&
+		idempotencyKey.RequestHash != "" &&
+		idempotencyKey.ResponseStatus == http.StatusCreated &&
+		idempotencyKey.ExpiresAt.After(time.Now()) &&
+		idempotencyKey.NewResponse != nil
+}
+
+func (e eqTransferTxIdempotencyKeyMatcher) String() string {
+	return fmt.Sprintf("matches idempotency key %v of user %v", e.key, e.username)
+}
+
+func TestTransferIdempotencyKey(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.GBP
+	account2.Currency = util.GBP
+
+	key := util.RandomString(16)
+	req := transferRequest{
+		Amount:        "0.10",
+		Currency:      util.GBP,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	}
+
+	requestHash, err := hashRequest(req)
+	assert.NoError(t, err)
+
+	result := newTransferTxResult(account1, account2, 10, 10, money.OneRate)
+	responseBody, err := json.Marshal(newTransferTxResponse(result))
+	assert.NoError(t, err)
+
+	storedKey := db.IdempotencyKey{
+		Key:            key,
+		Username:       user1.Username,
+		RequestPath:    "/transfers",
+		RequestHash:    requestHash,
+		ResponseStatus: http.StatusCreated,
+		ResponseBody:   responseBody,
+	}
+
+	getKeyArg := db.GetIdempotencyKeyParams{
+		Username: user1.Username,
+		Key:      key,
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		req           transferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstRequest",
+			key:  key,
+			req:  req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).Times(1).Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), eqTransferTxIdempotencyKeyMatcher{key, user1.Username}).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.JSONEq(t, string(responseBody), recorder.Body.String())
+			},
+		},
+		{
+			name: "Replay",
+			key:  key,
+			req:  req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).Times(1).Return(storedKey, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.JSONEq(t, string(responseBody), recorder.Body.String())
+			},
+		},
+		{
+			name: "MismatchedRequest",
+			key:  key,
+			req: transferRequest{
+				Amount:        "0.11",
+				Currency:      req.Currency,
+				FromAccountID: req.FromAccountID,
+				ToAccountID:   req.ToAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).Times(1).Return(storedKey, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentRequest",
+			key:  key,
+			req:  req,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).Times(1).Return(db.IdempotencyKey{}, db.ErrRecordNotFound),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getKeyArg)).Times(1).Return(storedKey, nil),
+				)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrIdempotencyKeyInUse)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assert.JSONEq(t, string(responseBody), recorder.Body.String())
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  strings.Repeat("k", maxIdempotencyKeyLength+1),
+			req:  req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LookupError",
+			key:  key,
+			req:  req,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.req)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			request.Header.Set(idempotencyKeyHeader, tc.key)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
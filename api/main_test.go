@@ -3,10 +3,42 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/registry"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/worker"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 )
 
+func newTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
+	config := util.Config{
+		TokenSymmetricKey:      util.RandomString(32),
+		CursorSigningKey:       util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RefreshTokenDuration:   time.Hour,
+		IdempotencyKeyDuration: time.Hour,
+		FXRates:                "GBP/USD=1.25",
+	}
+
+	// the access tokens of addAuthorization belong to an active session
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetSession(gomock.Any(), gomock.Eq(testSessionID)).
+			AnyTimes().
+			Return(db.Session{ID: testSessionID}, nil)
+	}
+
+	server, err := NewServer(config, store, taskDistributor, registry.NewCurrencyRegistry(store))
+	assert.NoError(t, err)
+
+	return server
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
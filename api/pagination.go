@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/drmanalo/simplebank/pagination"
+)
+
+// decodeCursor returns the position to continue from, or the zero cursor for the first page
+func (server *Server) decodeCursor(scope string, value string) (pagination.Cursor, error) {
+	if value == "" {
+		return pagination.Cursor{}, nil
+	}
+	return server.cursorSigner.Decode(scope, value)
+}
+
+// encodeCursor returns the next_cursor for a response, which is empty on the last page
+func (server *Server) encodeCursor(scope string, next *pagination.Cursor) string {
+	if next == nil {
+		return ""
+	}
+	return server.cursorSigner.Encode(scope, *next)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type scheduledTransferResponse struct {
+	ID            int64        `json:"id"`
+	FromAccountID int64        `json:"from_account_id"`
+	ToAccountID   int64        `json:"to_account_id"`
+	Amount        money.Amount `json:"amount"`
+	ExecuteAt     time.Time    `json:"execute_at"`
+	Status        string       `json:"status"`
+	// TransferID is set once the transfer is executed, and FailureReason if it failed
+	TransferID    int64              `json:"transfer_id,omitempty"`
+	FailureReason string             `json:"failure_reason,omitempty"`
+	ProcessedAt   pgtype.Timestamptz `json:"processed_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+func newScheduledTransferResponse(transfer db.ScheduledTransfer, currency string) scheduledTransferResponse {
+	return scheduledTransferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, currency),
+		ExecuteAt:     transfer.ExecuteAt,
+		Status:        transfer.Status,
+		TransferID:    transfer.TransferID.Int64,
+		FailureReason: transfer.FailureReason.String,
+		ProcessedAt:   transfer.ProcessedAt,
+		CreatedAt:     transfer.CreatedAt,
+	}
+}
+
+// cancelScheduledTransfer stops a pending scheduled transfer from executing.
+// If the scheduler is executing it at that moment, the cancel waits for it and then fails with a conflict
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.GetScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	account, ok := server.authorizedAccount(ctx, scheduled.FromAccountID)
+	if !ok {
+		return
+	}
+
+	cancelled, err := server.store.FinishScheduledTransfer(ctx, db.FinishScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: db.ScheduledTransferCancelled,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := fmt.Errorf("scheduled transfer [%d] is no longer pending", scheduled.ID)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(cancelled, account.Currency))
+}
@@ -1,7 +1,17 @@
 package api
 
 import (
+	"fmt"
+
 	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/export"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/registry"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -9,28 +19,102 @@ import (
 
 // Server serves HTTP requests for our banking service
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config          util.Config
+	store           db.Store
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+	service         *service.Service
+	currencies      *registry.CurrencyRegistry
+	cursorSigner    *pagination.Signer
+	router          *gin.Engine
 }
 
 // NewServer creates a new HTTP server and set up routing
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
-	router := gin.Default()
+func NewServer(
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+	currencies *registry.CurrencyRegistry,
+) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+	}
+
+	cursorSigner, err := pagination.NewSigner(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor signer: %w", err)
+	}
+
+	server := &Server{
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		taskDistributor: taskDistributor,
+		service:         service.New(store, rateProvider),
+		currencies:      currencies,
+		cursorSigner:    cursorSigner,
+	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("currency", validCurrency(currencies))
 	}
 
-	router.GET("/accounts/:id", server.getAccount)
-	router.POST("/accounts", server.createAccount)
-	router.GET("/accounts", server.listAccount)
+	server.setupRouter()
+	return server, nil
+}
+
+func (server *Server) setupRouter() {
+	router := gin.Default()
 
-	router.POST("/transfers", server.createTransfer)
 	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router.GET("/verify_email", server.verifyEmail)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts", server.listAccount)
+	authRoutes.POST("/accounts/:id/freeze", adminMiddleware(server.store), server.changeAccountStatus(db.AccountStatusFrozen))
+	authRoutes.POST("/accounts/:id/unfreeze", adminMiddleware(server.store), server.changeAccountStatus(db.AccountStatusActive))
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.GET("/accounts/:id/statement", server.getStatement)
+	authRoutes.GET("/accounts/:id/statement.csv", server.exportStatement(export.CSV))
+	authRoutes.GET("/accounts/:id/statement.ofx", server.exportStatement(export.OFX))
+	authRoutes.GET("/accounts/:id/statement.xml", server.exportStatement(export.CAMT053))
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.DELETE("/transfers/scheduled/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/entries/:id", server.getEntry)
+
+	authRoutes.POST("/standing_orders", server.createStandingOrder)
+	authRoutes.GET("/standing_orders", server.listStandingOrders)
+	authRoutes.GET("/standing_orders/:id", server.getStandingOrder)
+	authRoutes.PATCH("/standing_orders/:id", server.updateStandingOrder)
+	authRoutes.DELETE("/standing_orders/:id", server.cancelStandingOrder)
+
+	authRoutes.GET("/users/sessions", server.listSessions)
+	authRoutes.DELETE("/users/sessions/:id", server.deleteSession)
+	authRoutes.POST("/users/logout", server.logoutUser)
+	authRoutes.POST("/users/logout_all", server.logoutAll)
+
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker, server.store), adminMiddleware(server.store))
+
+	adminRoutes.GET("/currencies", server.listCurrencies)
+	adminRoutes.PATCH("/currencies/:code", server.updateCurrency)
 
 	server.router = router
-	return server
 }
 
 // Start runs the HTTP server on a specific address
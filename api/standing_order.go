@@ -0,0 +1,370 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// createStandingOrderRequest pays Amount every Interval days, weeks or months from StartAt.
+// The order ends at EndAt or after MaxOccurrences payments, whichever comes first, or runs until cancelled
+type createStandingOrderRequest struct {
+	// Amount is a decimal string in Currency, e.g. "12.50"
+	Amount        string `json:"amount" binding:"required"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	// CrossCurrency lets the to account hold a different currency.
+	// Each payment is converted at the rate of the moment it is made
+	CrossCurrency bool   `json:"cross_currency"`
+	Frequency     string `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	// Interval defaults to 1. A monthly order starting on the 31st is paid on the last day of shorter months
+	Interval       int32     `json:"interval" binding:"omitempty,min=1"`
+	StartAt        time.Time `json:"start_at"`
+	EndAt          time.Time `json:"end_at" binding:"omitempty,gtfield=StartAt"`
+	MaxOccurrences int32     `json:"max_occurrences" binding:"omitempty,min=1"`
+	// TimeZone is the IANA time zone, e.g. "Europe/London", whose day and time of day
+	// every payment keeps from StartAt. It defaults to UTC
+	TimeZone string `json:"time_zone" binding:"omitempty,timezone"`
+}
+
+type getStandingOrderRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listStandingOrdersRequest struct {
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor   string `form:"cursor"`
+}
+
+type listStandingOrdersResponse struct {
+	StandingOrders []standingOrderResponse `json:"standing_orders"`
+	NextCursor     string                  `json:"next_cursor,omitempty"`
+}
+
+// updateStandingOrderRequest changes the fields that are set. Amount is in the currency of the from account.
+// Lowering MaxOccurrences or moving EndAt so that no payment is left completes the order
+type updateStandingOrderRequest struct {
+	Amount         string    `json:"amount"`
+	EndAt          time.Time `json:"end_at"`
+	MaxOccurrences int32     `json:"max_occurrences" binding:"omitempty,min=1"`
+}
+
+type standingOrderResponse struct {
+	ID             int64              `json:"id"`
+	FromAccountID  int64              `json:"from_account_id"`
+	ToAccountID    int64              `json:"to_account_id"`
+	Amount         money.Amount       `json:"amount"`
+	Frequency      string             `json:"frequency"`
+	Interval       int32              `json:"interval"`
+	StartAt        time.Time          `json:"start_at"`
+	EndAt          pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences int32              `json:"max_occurrences,omitempty"`
+	TimeZone       string             `json:"time_zone"`
+	// Occurrences counts the payments made. SkippedOccurrences counts the ones given up on
+	// after their retries, which don't count towards MaxOccurrences
+	Occurrences        int32 `json:"occurrences"`
+	SkippedOccurrences int32 `json:"skipped_occurrences"`
+	// NextRunAt is the next payment, or the next retry of one that failed with LastError.
+	// It is null once the order has completed or been cancelled
+	NextRunAt  pgtype.Timestamptz `json:"next_run_at"`
+	RetryCount int32              `json:"retry_count"`
+	LastError  string             `json:"last_error,omitempty"`
+	Status     string             `json:"status"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func newStandingOrderResponse(order db.StandingOrder, currency string) standingOrderResponse {
+	return standingOrderResponse{
+		ID:                 order.ID,
+		FromAccountID:      order.FromAccountID,
+		ToAccountID:        order.ToAccountID,
+		Amount:             money.New(order.Amount, currency),
+		Frequency:          order.Frequency,
+		Interval:           order.Interval,
+		StartAt:            order.StartAt,
+		EndAt:              order.EndAt,
+		MaxOccurrences:     order.MaxOccurrences.Int32,
+		TimeZone:           order.TimeZone,
+		Occurrences:        order.Occurrences,
+		SkippedOccurrences: order.SkippedOccurrences,
+		NextRunAt:          order.NextRunAt,
+		RetryCount:         order.RetryCount,
+		LastError:          order.LastError.String,
+		Status:             order.Status,
+		CreatedAt:          order.CreatedAt,
+	}
+}
+
+// createStandingOrder validates the accounts the way createTransfer does.
+// Balances are only checked when each payment is made
+func (server *Server) createStandingOrder(ctx *gin.Context) {
+	var req createStandingOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !amount.IsPositive() {
+		err := errors.New("amount must be greater than 0")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, req, http.StatusCreated, func(result interface{}) interface{} {
+		return newStandingOrderResponse(result.(db.StandingOrder), req.Currency)
+	})
+	if !ok {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toCurrency := req.Currency
+	if req.CrossCurrency {
+		toCurrency = ""
+	}
+
+	if _, valid := server.validAccount(ctx, req.ToAccountID, toCurrency); !valid {
+		return
+	}
+
+	interval := req.Interval
+	if interval == 0 {
+		interval = 1
+	}
+
+	timeZone := req.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	arg := db.CreateStandingOrderTxParams{
+		CreateStandingOrderParams: db.CreateStandingOrderParams{
+			FromAccountID:  req.FromAccountID,
+			ToAccountID:    req.ToAccountID,
+			Amount:         amount.Minor,
+			Frequency:      req.Frequency,
+			Interval:       interval,
+			StartAt:        req.StartAt,
+			EndAt:          pgtype.Timestamptz{Time: req.EndAt, Valid: !req.EndAt.IsZero()},
+			MaxOccurrences: pgtype.Int4{Int32: req.MaxOccurrences, Valid: req.MaxOccurrences != 0},
+			TimeZone:       timeZone,
+		},
+		IdempotencyKey: idempotencyKey,
+	}
+
+	result, err := server.store.CreateStandingOrderTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			server.idempotencyKeyInUse(ctx, idempotencyKey)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newStandingOrderResponse(result, fromAccount.Currency))
+}
+
+// authorizedStandingOrder looks up a standing order and checks that the authenticated user owns its from account.
+// Like authorizedAccount, it writes the error response itself
+func (server *Server) authorizedStandingOrder(ctx *gin.Context, id int64) (db.StandingOrder, db.Account, bool) {
+	order, err := server.store.GetStandingOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return order, db.Account{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return order, db.Account{}, false
+	}
+
+	account, ok := server.authorizedAccount(ctx, order.FromAccountID)
+	return order, account, ok
+}
+
+func (server *Server) getStandingOrder(ctx *gin.Context) {
+	var req getStandingOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, account, ok := server.authorizedStandingOrder(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(order, account.Currency))
+}
+
+// listStandingOrders returns the standing orders paid from the accounts of the authenticated user
+// in (created_at, id) order
+func (server *Server) listStandingOrders(ctx *gin.Context) {
+	var req listStandingOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scope := pagination.StandingOrdersScope(authPayload.Username)
+	after, err := server.decodeCursor(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// fetch one extra row to find out whether there is another page
+	rows, err := server.store.ListStandingOrders(ctx, db.ListStandingOrdersParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		RowLimit:       req.PageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rows, next := pagination.Page(rows, req.PageSize, func(row db.ListStandingOrdersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.StandingOrder.CreatedAt, ID: row.StandingOrder.ID}
+	})
+
+	resp := listStandingOrdersResponse{
+		StandingOrders: make([]standingOrderResponse, len(rows)),
+		NextCursor:     server.encodeCursor(scope, next),
+	}
+	for i, row := range rows {
+		resp.StandingOrders[i] = newStandingOrderResponse(row.StandingOrder, row.Currency)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) updateStandingOrder(ctx *gin.Context) {
+	var uri getStandingOrderRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateStandingOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Amount == "" && req.EndAt.IsZero() && req.MaxOccurrences == 0 {
+		err := errors.New("nothing to update: set amount, end_at or max_occurrences")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, account, ok := server.authorizedStandingOrder(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateStandingOrderTxParams{
+		ID:             order.ID,
+		MaxOccurrences: pgtype.Int4{Int32: req.MaxOccurrences, Valid: req.MaxOccurrences != 0},
+	}
+
+	if req.Amount != "" {
+		amount, err := money.Parse(req.Amount, account.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		if !amount.IsPositive() {
+			err := errors.New("amount must be greater than 0")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		arg.Amount = pgtype.Int8{Int64: amount.Minor, Valid: true}
+	}
+
+	if !req.EndAt.IsZero() {
+		if !req.EndAt.After(order.StartAt) {
+			err := errors.New("end_at must be after start_at")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		arg.EndAt = pgtype.Timestamptz{Time: req.EndAt, Valid: true}
+	}
+
+	updated, err := server.store.UpdateStandingOrderTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrStandingOrderNotActive) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(updated, account.Currency))
+}
+
+// cancelStandingOrder stops an active standing order. Payments already made are not affected.
+// If the worker is paying it at that moment, the cancel waits for it to finish
+func (server *Server) cancelStandingOrder(ctx *gin.Context) {
+	var req getStandingOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, account, ok := server.authorizedStandingOrder(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	cancelled, err := server.store.CancelStandingOrder(ctx, order.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := fmt.Errorf("standing order [%d] is no longer active", order.ID)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(cancelled, account.Currency))
+}
@@ -0,0 +1,713 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomStandingOrder(account db.Account) db.StandingOrder {
+	return db.StandingOrder{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 1,
+		Amount:        util.RandomInt(1, 1000),
+		Frequency:     "monthly",
+		Interval:      1,
+		StartAt:       time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		TimeZone:      "UTC",
+		NextRunAt:     pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		Status:        db.StandingOrderActive,
+		CreatedAt:     time.Now().Truncate(time.Second).UTC(),
+	}
+}
+
+func TestCreateStandingOrderAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account1.Currency = util.GBP
+	account2.Currency = util.GBP
+	account3.Currency = util.USD
+
+	amount := int64(95000)
+	amountValue := money.New(amount, util.GBP).String()
+	startAt := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	endAt := startAt.AddDate(1, 0, 0)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "monthly",
+				"start_at":        startAt,
+				"end_at":          endAt,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateStandingOrderTxParams{
+					CreateStandingOrderParams: db.CreateStandingOrderParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Frequency:     "monthly",
+						Interval:      1,
+						StartAt:       startAt,
+						EndAt:         pgtype.Timestamptz{Time: endAt, Valid: true},
+						TimeZone:      "UTC",
+					},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CreateStandingOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.StandingOrder{
+						ID:            1,
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Frequency:     "monthly",
+						Interval:      1,
+						StartAt:       startAt,
+						EndAt:         pgtype.Timestamptz{Time: endAt, Valid: true},
+						TimeZone:      "UTC",
+						NextRunAt:     pgtype.Timestamptz{Time: startAt, Valid: true},
+						Status:        db.StandingOrderActive,
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got map[string]interface{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				assert.Equal(t, amountValue, got["amount"])
+				assert.Equal(t, db.StandingOrderActive, got["status"])
+				assert.Equal(t, startAt.Format(time.RFC3339), got["next_run_at"])
+				assert.Equal(t, "UTC", got["time_zone"])
+				assert.Equal(t, float64(0), got["skipped_occurrences"])
+			},
+		},
+		{
+			name: "CrossCurrency",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"cross_currency":  true,
+				"frequency":       "weekly",
+				"interval":        2,
+				"start_at":        startAt,
+				"max_occurrences": 10,
+				"time_zone":       "Europe/London",
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateStandingOrderTxParams{
+					CreateStandingOrderParams: db.CreateStandingOrderParams{
+						FromAccountID:  account1.ID,
+						ToAccountID:    account3.ID,
+						Amount:         amount,
+						Frequency:      "weekly",
+						Interval:       2,
+						StartAt:        startAt,
+						MaxOccurrences: pgtype.Int4{Int32: 10, Valid: true},
+						TimeZone:       "Europe/London",
+					},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					CreateStandingOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.StandingOrder{ID: 1, Amount: amount, Status: db.StandingOrderActive}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"frequency":       "monthly",
+				"start_at":        startAt,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "monthly",
+				"start_at":        startAt,
+			},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "StartAtInPast",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "monthly",
+				"start_at":        time.Now().Add(-time.Hour),
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EndAtBeforeStartAt",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "monthly",
+				"start_at":        startAt,
+				"end_at":          startAt.Add(-time.Minute),
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTimeZone",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "monthly",
+				"start_at":        startAt,
+				"time_zone":       "Europe/Atlantis",
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidFrequency",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "yearly",
+				"start_at":        startAt,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"frequency":       "daily",
+				"start_at":        startAt,
+			},
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CreateStandingOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.StandingOrder{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/standing_orders", bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetStandingOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	order := randomStandingOrder(account)
+
+	testCases := []struct {
+		name          string
+		id            int64
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			id:       order.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				assert.Equal(t, money.New(order.Amount, account.Currency).String(), got["amount"])
+				assert.Equal(t, float64(order.ID), got["id"])
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			id:       order.ID,
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			id:       order.ID,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(db.StandingOrder{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidID",
+			id:       0,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/standing_orders/%d", tc.id)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListStandingOrdersAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	n := 6
+	rows := make([]db.ListStandingOrdersRow, n)
+	for i := range rows {
+		order := randomStandingOrder(account)
+		order.ID = int64(i + 1)
+		order.CreatedAt = order.CreatedAt.Add(time.Duration(i) * time.Second)
+		rows[i] = db.ListStandingOrdersRow{StandingOrder: order, Currency: account.Currency}
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListStandingOrders(gomock.Any(), gomock.Eq(db.ListStandingOrdersParams{Owner: user.Username, RowLimit: 6})).
+			Times(1).
+			Return(rows, nil),
+		store.EXPECT().
+			ListStandingOrders(gomock.Any(), gomock.Eq(db.ListStandingOrdersParams{
+				Owner:          user.Username,
+				AfterCreatedAt: rows[4].StandingOrder.CreatedAt,
+				AfterID:        rows[4].StandingOrder.ID,
+				RowLimit:       6,
+			})).
+			Times(1).
+			Return(rows[5:], nil),
+	)
+
+	server := newTestServer(t, store, nil)
+
+	type page struct {
+		StandingOrders []struct {
+			ID int64 `json:"id"`
+		} `json:"standing_orders"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	list := func(cursor string) page {
+		recorder := httptest.NewRecorder()
+		url := "/standing_orders?page_size=5"
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var resp page
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+		return resp
+	}
+
+	first := list("")
+	assert.Len(t, first.StandingOrders, 5)
+	assert.NotEmpty(t, first.NextCursor)
+
+	last := list(first.NextCursor)
+	assert.Len(t, last.StandingOrders, 1)
+	assert.Equal(t, rows[5].StandingOrder.ID, last.StandingOrders[0].ID)
+	assert.Empty(t, last.NextCursor)
+}
+
+func TestUpdateStandingOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.GBP
+	order := randomStandingOrder(account)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     gin.H{"amount": "1000.00", "max_occurrences": 12},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateStandingOrderTxParams{
+					ID:             order.ID,
+					Amount:         pgtype.Int8{Int64: 100000, Valid: true},
+					MaxOccurrences: pgtype.Int4{Int32: 12, Valid: true},
+				}
+				updated := order
+				updated.Amount = 100000
+				updated.MaxOccurrences = arg.MaxOccurrences
+
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"amount":"1000.00"`)
+				assert.Contains(t, recorder.Body.String(), `"max_occurrences":12`)
+			},
+		},
+		{
+			name:     "NotActive",
+			body:     gin.H{"amount": "1000.00"},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateStandingOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.StandingOrder{}, db.ErrStandingOrderNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "EndAtBeforeStartAt",
+			body:     gin.H{"end_at": order.StartAt.Add(-time.Hour)},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidAmount",
+			body:     gin.H{"amount": "-5.00"},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NothingToUpdate",
+			body:     gin.H{},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			body:     gin.H{"amount": "1000.00"},
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/standing_orders/%d", order.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelStandingOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	order := randomStandingOrder(account)
+
+	cancelled := order
+	cancelled.Status = db.StandingOrderCancelled
+	cancelled.NextRunAt = pgtype.Timestamptz{}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CancelStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(cancelled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"status":"cancelled"`)
+				assert.Contains(t, recorder.Body.String(), `"next_run_at":null`)
+			},
+		},
+		{
+			name:     "NotActive",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CancelStandingOrder(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(db.StandingOrder{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CancelStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CancelStandingOrder(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.StandingOrder{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/standing_orders/%d", order.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
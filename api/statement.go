@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/export"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type getStatementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getStatementRequest is the statement period. Both times are RFC 3339,
+// from is inclusive and to is exclusive
+type getStatementRequest struct {
+	From time.Time `form:"from" binding:"required"`
+	To   time.Time `form:"to" binding:"required,gtfield=From"`
+}
+
+type statementLineResponse struct {
+	EntryID               int64              `json:"entry_id"`
+	Amount                money.Amount       `json:"amount"`
+	RunningBalance        money.Amount       `json:"running_balance"`
+	TransferID            *int64             `json:"transfer_id"`
+	CounterpartyAccountID *int64             `json:"counterparty_account_id"`
+	CounterpartyOwner     *string            `json:"counterparty_owner"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+}
+
+type statementResponse struct {
+	AccountID      int64                   `json:"account_id"`
+	Currency       string                  `json:"currency"`
+	From           time.Time               `json:"from"`
+	To             time.Time               `json:"to"`
+	OpeningBalance money.Amount            `json:"opening_balance"`
+	ClosingBalance money.Amount            `json:"closing_balance"`
+	Lines          []statementLineResponse `json:"lines"`
+}
+
+func newStatementLineResponse(line db.ListStatementLinesRow, currency string) statementLineResponse {
+	resp := statementLineResponse{
+		EntryID:        line.EntryID,
+		Amount:         money.New(line.Amount, currency),
+		RunningBalance: money.New(line.RunningBalance, currency),
+		CreatedAt:      line.CreatedAt,
+	}
+	if line.TransferID.Valid {
+		resp.TransferID = &line.TransferID.Int64
+	}
+	if line.CounterpartyAccountID.Valid {
+		resp.CounterpartyAccountID = &line.CounterpartyAccountID.Int64
+	}
+	if line.CounterpartyOwner.Valid {
+		resp.CounterpartyOwner = &line.CounterpartyOwner.String
+	}
+	return resp
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri getStatementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.authorizedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	from := pgtype.Timestamptz{Time: req.From, Valid: true}
+	lines, err := server.store.ListStatementLines(ctx, db.ListStatementLinesParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    pgtype.Timestamptz{Time: req.To, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the running balance already includes the line's own amount,
+	// so the opening balance is only queried for an empty period
+	var opening, closing int64
+	if len(lines) > 0 {
+		opening = lines[0].RunningBalance - lines[0].Amount
+		closing = lines[len(lines)-1].RunningBalance
+	} else {
+		opening, err = server.store.GetStatementOpeningBalance(ctx, db.GetStatementOpeningBalanceParams{
+			FromTime:  from,
+			AccountID: account.ID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		closing = opening
+	}
+
+	resp := statementResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		From:           req.From,
+		To:             req.To,
+		OpeningBalance: money.New(opening, account.Currency),
+		ClosingBalance: money.New(closing, account.Currency),
+		Lines:          make([]statementLineResponse, len(lines)),
+	}
+	for i, line := range lines {
+		resp.Lines[i] = newStatementLineResponse(line, account.Currency)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func newExportLine(line db.ListStatementLinesRow, currency string) export.Line {
+	return export.Line{
+		EntryID:               line.EntryID,
+		Amount:                money.New(line.Amount, currency),
+		RunningBalance:        money.New(line.RunningBalance, currency),
+		TransferID:            line.TransferID.Int64,
+		CounterpartyAccountID: line.CounterpartyAccountID.Int64,
+		CounterpartyOwner:     line.CounterpartyOwner.String,
+		CreatedAt:             line.CreatedAt.Time,
+	}
+}
+
+// exportStatement serves the statement as a file download in the given format.
+// Lines are streamed from the database straight into the response rather than loaded up front
+func (server *Server) exportStatement(format export.Format) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var uri getStatementURI
+		if err := ctx.ShouldBindUri(&uri); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		var req getStatementRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		account, ok := server.authorizedAccount(ctx, uri.ID)
+		if !ok {
+			return
+		}
+
+		filename := fmt.Sprintf("statement-%d-%s-%s.%s",
+			account.ID,
+			req.From.UTC().Format("20060102"),
+			req.To.UTC().Format("20060102"),
+			format,
+		)
+
+		// camt.053 puts both balances before the entries, so they are read before streaming.
+		// Balances and lines come from one snapshot, so they always add up
+		err := server.store.ExportStatementTx(ctx, db.ExportStatementTxParams{
+			AccountID: account.ID,
+			FromTime:  pgtype.Timestamptz{Time: req.From, Valid: true},
+			ToTime:    pgtype.Timestamptz{Time: req.To, Valid: true},
+			Write: func(balances db.StatementBalances, streamLines func(fn func(db.ListStatementLinesRow) error) error) error {
+				statement := export.Statement{
+					AccountID:      account.ID,
+					Owner:          account.Owner,
+					Currency:       account.Currency,
+					From:           req.From,
+					To:             req.To,
+					OpeningBalance: money.New(balances.OpeningBalance, account.Currency),
+					ClosingBalance: money.New(balances.ClosingBalance, account.Currency),
+					GeneratedAt:    time.Now(),
+				}
+
+				lines := func(fn func(export.Line) error) error {
+					return streamLines(func(line db.ListStatementLinesRow) error {
+						return fn(newExportLine(line, account.Currency))
+					})
+				}
+
+				ctx.Header("Content-Type", format.ContentType())
+				ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+				ctx.Status(http.StatusOK)
+
+				// nothing reaches the client until the buffer first fills,
+				// so a failure early on can still be reported as a normal error response
+				w := bufio.NewWriter(ctx.Writer)
+				err := export.Write(w, format, statement, lines)
+				if err != nil {
+					return err
+				}
+				return w.Flush()
+			},
+		})
+		if err != nil {
+			if !ctx.Writer.Written() {
+				ctx.Writer.Header().Del("Content-Type")
+				ctx.Writer.Header().Del("Content-Disposition")
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			// the status has already been sent, so the best we can do is cut the download short
+			_ = ctx.Error(err)
+			ctx.Abort()
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "USD"
+	counterparty := randomAccount(user2.Username)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	lines := []db.ListStatementLinesRow{
+		{
+			EntryID:               1,
+			Amount:                250,
+			CreatedAt:             pgtype.Timestamptz{Time: from.Add(time.Hour), Valid: true},
+			TransferID:            pgtype.Int8{Int64: 10, Valid: true},
+			CounterpartyAccountID: pgtype.Int8{Int64: counterparty.ID, Valid: true},
+			CounterpartyOwner:     pgtype.Text{String: counterparty.Owner, Valid: true},
+			RunningBalance:        1250,
+		},
+		{
+			EntryID:        2,
+			Amount:         -100,
+			CreatedAt:      pgtype.Timestamptz{Time: from.Add(2 * time.Hour), Valid: true},
+			RunningBalance: 1150,
+		},
+	}
+
+	type Query struct {
+		from string
+		to   string
+	}
+
+	validQuery := Query{
+		from: from.Format(time.RFC3339),
+		to:   to.Format(time.RFC3339),
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				arg := db.ListStatementLinesParams{
+					AccountID: account.ID,
+					FromTime:  pgtype.Timestamptz{Time: from, Valid: true},
+					ToTime:    pgtype.Timestamptz{Time: to, Valid: true},
+				}
+				store.EXPECT().
+					ListStatementLines(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(lines, nil)
+				store.EXPECT().
+					GetStatementOpeningBalance(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, "10.00", got["opening_balance"])
+				assert.Equal(t, "11.50", got["closing_balance"])
+
+				gotLines := got["lines"].([]interface{})
+				assert.Len(t, gotLines, 2)
+
+				line := gotLines[0].(map[string]interface{})
+				assert.Equal(t, "2.50", line["amount"])
+				assert.Equal(t, "12.50", line["running_balance"])
+				assert.Equal(t, float64(10), line["transfer_id"])
+				assert.Equal(t, float64(counterparty.ID), line["counterparty_account_id"])
+				assert.Equal(t, counterparty.Owner, line["counterparty_owner"])
+
+				line = gotLines[1].(map[string]interface{})
+				assert.Equal(t, "-1.00", line["amount"])
+				assert.Equal(t, "11.50", line["running_balance"])
+				assert.Nil(t, line["transfer_id"])
+				assert.Nil(t, line["counterparty_account_id"])
+			},
+		},
+		{
+			name:      "EmptyPeriod",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListStatementLines(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListStatementLinesRow{}, nil)
+				arg := db.GetStatementOpeningBalanceParams{
+					FromTime:  pgtype.Timestamptz{Time: from, Valid: true},
+					AccountID: account.ID,
+				}
+				store.EXPECT().
+					GetStatementOpeningBalance(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(int64(500), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, "5.00", got["opening_balance"])
+				assert.Equal(t, "5.00", got["closing_balance"])
+				assert.Empty(t, got["lines"])
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListStatementLines(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "ToBeforeFrom",
+			accountID: account.ID,
+			query: Query{
+				from: to.Format(time.RFC3339),
+				to:   from.Format(time.RFC3339),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidFrom",
+			accountID: account.ID,
+			query: Query{
+				from: "yesterday",
+				to:   validQuery.to,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			query:     validQuery,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListStatementLines(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListStatementLinesRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("from", tc.query.from)
+			q.Add("to", tc.query.to)
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "USD"
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	line := db.ListStatementLinesRow{
+		EntryID:               1,
+		Amount:                250,
+		CreatedAt:             pgtype.Timestamptz{Time: from.Add(time.Hour), Valid: true},
+		TransferID:            pgtype.Int8{Int64: 10, Valid: true},
+		CounterpartyAccountID: pgtype.Int8{Int64: 7, Valid: true},
+		CounterpartyOwner:     pgtype.Text{String: "bob", Valid: true},
+		RunningBalance:        1250,
+	}
+
+	balances := db.StatementBalances{OpeningBalance: 1000, ClosingBalance: 1250}
+
+	// exportStatement streams the lines from inside the transaction that read the balances
+	buildExportStubs := func(store *mockdb.MockStore, streamErr error) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+		store.EXPECT().
+			ExportStatementTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, arg db.ExportStatementTxParams) error {
+				assert.Equal(t, account.ID, arg.AccountID)
+				assert.Equal(t, pgtype.Timestamptz{Time: from, Valid: true}, arg.FromTime)
+				assert.Equal(t, pgtype.Timestamptz{Time: to, Valid: true}, arg.ToTime)
+
+				return arg.Write(balances, func(fn func(db.ListStatementLinesRow) error) error {
+					if streamErr != nil {
+						return streamErr
+					}
+					return fn(line)
+				})
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		extension     string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "CSV",
+			extension: "csv",
+			username:  user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildExportStubs(store, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				filename := fmt.Sprintf(`attachment; filename="statement-%d-20260101-20260201.csv"`, account.ID)
+				assert.Equal(t, filename, recorder.Header().Get("Content-Disposition"))
+				assert.Contains(t, recorder.Body.String(), "2026-01-01T01:00:00Z,1,10,7,bob,2.50,12.50,USD\n")
+			},
+		},
+		{
+			name:      "OFX",
+			extension: "ofx",
+			username:  user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildExportStubs(store, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				assert.Contains(t, recorder.Body.String(), "<BALAMT>12.50</BALAMT>")
+			},
+		},
+		{
+			name:      "CAMT053",
+			extension: "xml",
+			username:  user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildExportStubs(store, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "application/xml; charset=utf-8", recorder.Header().Get("Content-Type"))
+				assert.Contains(t, recorder.Body.String(), `<Amt Ccy="USD">10.00</Amt>`)
+			},
+		},
+		{
+			name:      "StreamError",
+			extension: "csv",
+			username:  user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildExportStubs(store, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+				assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+				assert.Empty(t, recorder.Header().Get("Content-Disposition"))
+			},
+		},
+		{
+			name:      "BalanceError",
+			extension: "csv",
+			username:  user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				// the balances could not be read, so nothing is written
+				store.EXPECT().
+					ExportStatementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			extension: "csv",
+			username:  "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ExportStatementTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement.%s", account.ID, tc.extension)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("from", from.Format(time.RFC3339))
+			q.Add("to", to.Format(time.RFC3339))
+			request.URL.RawQuery = q.Encode()
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
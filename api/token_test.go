@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		duration      time.Duration
+		tokenType     token.TokenType
+		buildSession  func(refreshToken string, payload *token.Payload) db.Session
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().TouchSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assertBodyMatchRenewAccessToken(t, recorder.Body)
+			},
+		},
+		{
+			name:         "DisabledUser",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				disabled := user
+				disabled.IsDisabled = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(disabled, nil)
+				store.EXPECT().TouchSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "GetUserError",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:         "ExpiredRefreshToken",
+			username:     user.Username,
+			duration:     -time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "AccessToken",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeAccessToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "SessionNotFound",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			username:     user.Username,
+			duration:     time.Minute,
+			tokenType:    token.TokenTypeRefreshToken,
+			buildSession: validSession,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "BlockedSession",
+			username:  user.Username,
+			duration:  time.Minute,
+			tokenType: token.TokenTypeRefreshToken,
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := validSession(refreshToken, payload)
+				session.IsBlocked = true
+				return session
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "IncorrectSessionUser",
+			username:  user.Username,
+			duration:  time.Minute,
+			tokenType: token.TokenTypeRefreshToken,
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := validSession(refreshToken, payload)
+				session.Username = "another"
+				return session
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "MismatchedSessionToken",
+			username:  user.Username,
+			duration:  time.Minute,
+			tokenType: token.TokenTypeRefreshToken,
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := validSession(refreshToken, payload)
+				session.RefreshToken = "mismatched"
+				return session
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "ExpiredSession",
+			username:  user.Username,
+			duration:  time.Minute,
+			tokenType: token.TokenTypeRefreshToken,
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				session := validSession(refreshToken, payload)
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				return session
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store, nil)
+
+			refreshToken, payload, err := server.tokenMaker.CreateToken(tc.username, uuid.New(), tc.tokenType, tc.duration)
+			assert.NoError(t, err)
+
+			session := tc.buildSession(refreshToken, payload)
+			tc.buildStubs(store, session)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			assert.NoError(t, err)
+
+			url := "/tokens/renew_access"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func validSession(refreshToken string, payload *token.Payload) db.Session {
+	return db.Session{
+		ID:           payload.SessionID,
+		Username:     payload.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+}
+
+func assertBodyMatchRenewAccessToken(t *testing.T, body *bytes.Buffer) {
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+
+	var gotResponse renewAccessTokenResponse
+	err = json.Unmarshal(data, &gotResponse)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, gotResponse.AccessToken)
+	assert.True(t, gotResponse.AccessTokenExpiresAt.After(time.Now()))
+}
@@ -4,16 +4,142 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/token"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type transferRequest struct {
-	Amount        int64  `json:"amount" binding:"required,gt=0" `
+	// Amount is a decimal string in Currency, e.g. "12.50"
+	Amount        string `json:"amount" binding:"required"`
 	Currency      string `json:"currency" binding:"required,currency"`
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	// CrossCurrency lets the to account hold a different currency.
+	// Currency must then match the from account and the amount is converted
+	CrossCurrency bool `json:"cross_currency"`
+	// ExecuteAt schedules the transfer instead of making it now.
+	// A scheduled cross-currency transfer is converted at the rate of the moment it executes
+	ExecuteAt time.Time `json:"execute_at"`
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listTransfersRequest filters the transfer history of the authenticated user.
+// MinAmount and MaxAmount are decimal strings in Currency, so they need it set
+type listTransfersRequest struct {
+	AccountID int64     `form:"account_id" binding:"omitempty,min=1"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	Currency  string    `form:"currency" binding:"required_with=MinAmount MaxAmount"`
+	MinAmount string    `form:"min_amount"`
+	MaxAmount string    `form:"max_amount"`
+	From      time.Time `form:"from"`
+	To        time.Time `form:"to" binding:"omitempty,gtfield=From"`
+	Sort      string    `form:"sort" binding:"omitempty,oneof=newest oldest"`
+	PageSize  int32     `form:"page_size" binding:"required,min=5,max=100"`
+	Cursor    string    `form:"cursor"`
+}
+
+type listTransfersResponse struct {
+	Transfers  []transferResponse `json:"transfers"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type transferResponse struct {
+	ID            int64              `json:"id"`
+	FromAccountID int64              `json:"from_account_id"`
+	ToAccountID   int64              `json:"to_account_id"`
+	Amount        money.Amount       `json:"amount"`
+	ToAmount      money.Amount       `json:"to_amount"`
+	ExchangeRate  money.Rate         `json:"exchange_rate"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	// ReversesTransferID and ReversalReason are only set on reversals.
+	// ReversalStatus is left out of transfer lists and of reversals themselves
+	ReversesTransferID int64                   `json:"reverses_transfer_id,omitempty"`
+	ReversalReason     string                  `json:"reversal_reason,omitempty"`
+	ReversalStatus     *reversalStatusResponse `json:"reversal_status,omitempty"`
+}
+
+// reversalStatusResponse is how much of a transfer has been paid back, in the currency of its to account
+type reversalStatusResponse struct {
+	Status          string       `json:"status"`
+	ReversedAmount  money.Amount `json:"reversed_amount"`
+	RemainingAmount money.Amount `json:"remaining_amount"`
+}
+
+type entryResponse struct {
+	ID        int64              `json:"id"`
+	AccountID int64              `json:"account_id"`
+	Amount    money.Amount       `json:"amount"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	ToEntry     entryResponse    `json:"to_entry"`
+}
+
+func newTransferResponse(transfer db.Transfer, fromCurrency string, toCurrency string) transferResponse {
+	return transferResponse{
+		ID:                 transfer.ID,
+		FromAccountID:      transfer.FromAccountID,
+		ToAccountID:        transfer.ToAccountID,
+		Amount:             money.New(transfer.Amount, fromCurrency),
+		ToAmount:           money.New(transfer.ToAmount, toCurrency),
+		ExchangeRate:       transfer.ExchangeRate,
+		CreatedAt:          transfer.CreatedAt,
+		ReversesTransferID: transfer.ReversesTransferID.Int64,
+		ReversalReason:     transfer.ReversalReason.String,
+	}
+}
+
+func newReversalStatusResponse(status *db.ReversalStatus, currency string) *reversalStatusResponse {
+	if status == nil {
+		return nil
+	}
+
+	return &reversalStatusResponse{
+		Status:          status.Status,
+		ReversedAmount:  money.New(status.ReversedAmount, currency),
+		RemainingAmount: money.New(status.RemainingAmount, currency),
+	}
+}
+
+func newEntryResponse(entry db.Entry, currency string) entryResponse {
+	return entryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		Amount:    money.New(entry.Amount, currency),
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+func newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+
+	transfer := newTransferResponse(result.Transfer, fromCurrency, toCurrency)
+	transfer.ReversalStatus = newReversalStatusResponse(result.ReversalStatus, toCurrency)
+
+	return transferTxResponse{
+		Transfer:    transfer,
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount:   newAccountResponse(result.ToAccount),
+		FromEntry:   newEntryResponse(result.FromEntry, fromCurrency),
+		ToEntry:     newEntryResponse(result.ToEntry, toCurrency),
+	}
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -23,45 +149,234 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validAccount(ctx, req.FromAccountID, req.Currency) {
+	amount, err := money.Parse(req.Amount, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if !server.validAccount(ctx, req.ToAccountID, req.Currency) {
+	if !amount.IsPositive() {
+		err := errors.New("amount must be greater than 0")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.TransferTxParams{
-		Amount:        req.Amount,
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
+	scheduled := !req.ExecuteAt.IsZero()
+	status := http.StatusCreated
+	if scheduled {
+		status = http.StatusAccepted
+	}
+
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, req, status, func(result interface{}) interface{} {
+		if scheduled {
+			return newScheduledTransferResponse(result.(db.ScheduledTransfer), req.Currency)
+		}
+		return newTransferTxResponse(result.(db.TransferTxResult))
+	})
+	if !ok {
+		return
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.service.Transfer(ctx, service.TransferParams{
+		Username:       authPayload.Username,
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         amount,
+		CrossCurrency:  req.CrossCurrency,
+		ExecuteAt:      req.ExecuteAt,
+		IdempotencyKey: idempotencyKey,
+	})
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			server.idempotencyKeyInUse(ctx, idempotencyKey)
+			return
+		}
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	if result.Scheduled != nil {
+		ctx.JSON(http.StatusAccepted, newScheduledTransferResponse(*result.Scheduled, req.Currency))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newTransferTxResponse(result.TransferTxResult))
+}
+
+// transferErrorStatus maps an error of service.Transfer to the HTTP status of the response
+func transferErrorStatus(err error) int {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest
+	}
+
+	if errors.Is(err, service.ErrNotAccountOwner) {
+		return http.StatusUnauthorized
+	}
+
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+
+	var accountStatusErr *db.AccountStatusError
+	if errors.Is(err, fx.ErrRateNotFound) || errors.Is(err, db.ErrInsufficientFunds) || errors.As(err, &accountStatusErr) {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransferDetails(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, result)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if transfer.FromOwner != authPayload.Username && transfer.ToOwner != authPayload.Username {
+		err := errors.New("transfer doesn't involve the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	resp := newTransferResponse(transfer.Transfer, transfer.FromCurrency, transfer.ToCurrency)
+	resp.ReversalStatus = newReversalStatusResponse(transfer.ReversalStatus(), transfer.ToCurrency)
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	minAmount, err := parseOptionalAmount(req.MinAmount, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	maxAmount, err := parseOptionalAmount(req.MaxAmount, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	newestFirst := req.Sort != "oldest"
+	scope := pagination.TransfersScope(authPayload.Username, newestFirst)
+
+	after, err := server.decodeCursor(scope, req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.AccountID != 0 {
+		if _, ok := server.authorizedAccount(ctx, req.AccountID); !ok {
+			return
+		}
+	}
+
+	// fetch one extra row to find out whether there is another page
+	arg := db.ListUserTransfersNewestFirstParams{
+		Owner:          authPayload.Username,
+		Direction:      pgtype.Text{String: req.Direction, Valid: req.Direction != ""},
+		AccountID:      pgtype.Int8{Int64: req.AccountID, Valid: req.AccountID != 0},
+		Currency:       pgtype.Text{String: req.Currency, Valid: req.Currency != ""},
+		MinAmount:      minAmount,
+		MaxAmount:      maxAmount,
+		FromTime:       pgtype.Timestamptz{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:         pgtype.Timestamptz{Time: req.To, Valid: !req.To.IsZero()},
+		AfterCreatedAt: pgtype.Timestamptz{Time: after.CreatedAt, Valid: req.Cursor != ""},
+		AfterID:        pgtype.Int8{Int64: after.ID, Valid: req.Cursor != ""},
+		RowLimit:       req.PageSize + 1,
+	}
+
+	transfers, err := server.listUserTransfers(ctx, arg, newestFirst)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	transfers, next := pagination.Page(transfers, req.PageSize, func(row db.ListUserTransfersNewestFirstRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.Transfer.CreatedAt.Time, ID: row.Transfer.ID}
+	})
+
+	resp := listTransfersResponse{
+		Transfers:  make([]transferResponse, len(transfers)),
+		NextCursor: server.encodeCursor(scope, next),
+	}
+	for i, row := range transfers {
+		resp.Transfers[i] = newTransferResponse(row.Transfer, row.FromCurrency, row.ToCurrency)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// listUserTransfers runs the query for the sort order. Both queries take the same filters and return the same columns
+func (server *Server) listUserTransfers(ctx *gin.Context, arg db.ListUserTransfersNewestFirstParams, newestFirst bool) ([]db.ListUserTransfersNewestFirstRow, error) {
+	if newestFirst {
+		return server.store.ListUserTransfersNewestFirst(ctx, arg)
+	}
+
+	rows, err := server.store.ListUserTransfersOldestFirst(ctx, db.ListUserTransfersOldestFirstParams(arg))
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]db.ListUserTransfersNewestFirstRow, len(rows))
+	for i, row := range rows {
+		transfers[i] = db.ListUserTransfersNewestFirstRow(row)
+	}
+	return transfers, nil
+}
+
+// parseOptionalAmount parses a decimal amount filter into minor units, leaving it null when empty
+func parseOptionalAmount(value string, currency string) (pgtype.Int8, error) {
+	if value == "" {
+		return pgtype.Int8{}, nil
+	}
+
+	amount, err := money.Parse(value, currency)
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+
+	return pgtype.Int8{Int64: amount.Minor, Valid: true}, nil
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+// validAccount loads the account and checks its currency. An empty currency accepts any account currency
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			return account, false
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		return account, false
 	}
 
-	if account.Currency != currency {
+	if currency != "" && account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return account, false
 	}
 
-	return true
+	return account, true
 }
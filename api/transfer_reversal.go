@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type reverseTransferRequest struct {
+	// TransferID comes from the URL. It is part of the request so the idempotency key hash covers it
+	TransferID int64 `json:"transfer_id"`
+	// Amount is a decimal string in the currency of the to account, e.g. "12.50".
+	// It can be left out to reverse whatever is left of the transfer
+	Amount string `json:"amount"`
+	Reason string `json:"reason" binding:"required,oneof=duplicate fraudulent requested_by_customer processing_error"`
+}
+
+type reverseTransferResponse struct {
+	Reversal transferTxResponse `json:"reversal"`
+	// Original is the reversed transfer with its reversal status after this reversal
+	Original transferResponse `json:"original"`
+}
+
+func newReverseTransferResponse(result db.ReverseTransferTxResult) reverseTransferResponse {
+	// the reversal runs the other way, so its to account holds the original from currency
+	fromCurrency := result.Reversal.ToAccount.Currency
+	toCurrency := result.Reversal.FromAccount.Currency
+
+	original := newTransferResponse(result.Original, fromCurrency, toCurrency)
+	original.ReversalStatus = newReversalStatusResponse(&result.OriginalStatus, toCurrency)
+
+	return reverseTransferResponse{
+		Reversal: newTransferTxResponse(result.Reversal),
+		Original: original,
+	}
+}
+
+// reverseTransfer pays a transfer back, in full or in part.
+// Only the owner of the to account can reverse it, as the money comes out of their account
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	req.TransferID = uri.ID
+
+	idempotencyKey, ok := server.checkIdempotencyKey(ctx, req, http.StatusCreated, func(result interface{}) interface{} {
+		return newReverseTransferResponse(result.(db.ReverseTransferTxResult))
+	})
+	if !ok {
+		return
+	}
+
+	transfer, err := server.store.GetTransferDetails(ctx, req.TransferID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if transfer.ToOwner != authPayload.Username {
+		err := errors.New("only the recipient of a transfer can reverse it")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	amount, err := parseOptionalAmount(req.Amount, transfer.ToCurrency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if amount.Valid && amount.Int64 <= 0 {
+		err := errors.New("amount must be greater than 0")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID:     req.TransferID,
+		Amount:         amount.Int64,
+		Reason:         req.Reason,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			server.idempotencyKeyInUse(ctx, idempotencyKey)
+			return
+		}
+		if isReversalError(err) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newReverseTransferResponse(result))
+}
+
+// isReversalError reports whether the reversal was refused because of the state of the transfer or its accounts
+func isReversalError(err error) bool {
+	var accountStatusErr *db.AccountStatusError
+	return errors.Is(err, db.ErrTransferIsReversal) ||
+		errors.Is(err, db.ErrTransferReversed) ||
+		errors.Is(err, db.ErrReversalExceedsTransfer) ||
+		errors.Is(err, db.ErrReversalTooSmall) ||
+		errors.Is(err, db.ErrInsufficientFunds) ||
+		errors.As(err, &accountStatusErr)
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseTransferAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := randomAccount(sender.Username)
+	fromAccount.Currency = util.USD
+	toAccount := randomAccount(recipient.Username)
+	toAccount.Currency = util.USD
+
+	transfer := db.GetTransferDetailsRow{
+		Transfer: db.Transfer{
+			ID:            util.RandomInt(1, 1000),
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        1000,
+			ToAmount:      1000,
+			ExchangeRate:  money.OneRate,
+		},
+		FromOwner:    sender.Username,
+		FromCurrency: util.USD,
+		ToOwner:      recipient.Username,
+		ToCurrency:   util.USD,
+	}
+
+	reversal := func(amount int64) db.ReverseTransferTxResult {
+		result := newTransferTxResult(toAccount, fromAccount, amount, amount, money.OneRate)
+		result.Transfer.ReversesTransferID = pgtype.Int8{Int64: transfer.Transfer.ID, Valid: true}
+		result.Transfer.ReversalReason = pgtype.Text{String: db.ReversalReasonDuplicate, Valid: true}
+
+		status := db.ReversalStatusPartial
+		if amount == transfer.Transfer.ToAmount {
+			status = db.ReversalStatusFull
+		}
+
+		return db.ReverseTransferTxResult{
+			Reversal: result,
+			Original: transfer.Transfer,
+			OriginalStatus: db.ReversalStatus{
+				Status:          status,
+				ReversedAmount:  amount,
+				RemainingAmount: transfer.Transfer.ToAmount - amount,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "Full",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReverseTransferTxParams{
+					TransferID: transfer.Transfer.ID,
+					Reason:     db.ReversalReasonDuplicate,
+				}
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reversal(1000), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assertBodyMatchReversal(t, recorder.Body, "10.00", db.ReversalStatusFull, "0.00")
+			},
+		},
+		{
+			name:       "Partial",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"amount": "2.50", "reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReverseTransferTxParams{
+					TransferID: transfer.Transfer.ID,
+					Amount:     250,
+					Reason:     db.ReversalReasonDuplicate,
+				}
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reversal(250), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assertBodyMatchReversal(t, recorder.Body, "2.50", db.ReversalStatusPartial, "7.50")
+			},
+		},
+		{
+			name:       "Sender",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   sender.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).
+					Times(1).
+					Return(db.GetTransferDetailsRow{}, db.ErrRecordNotFound)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidReason",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": "changed_my_mind"},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "ZeroAmount",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"amount": "0.00", "reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "ExceedsTransfer",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"amount": "10.01", "reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:       "AlreadyReversed",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferReversed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:       "InsufficientFunds",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalError",
+			transferID: transfer.Transfer.ID,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			body:       gin.H{"reason": db.ReversalReasonDuplicate},
+			username:   recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferDetails(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/transfers/%d/reverse", tc.transferID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func assertBodyMatchReversal(t *testing.T, body *bytes.Buffer, amount string, status string, remaining string) {
+	var gotResult struct {
+		Reversal struct {
+			Transfer struct {
+				Amount             string `json:"amount"`
+				ReversesTransferID int64  `json:"reverses_transfer_id"`
+				ReversalReason     string `json:"reversal_reason"`
+				ReversalStatus     *struct {
+				} `json:"reversal_status"`
+			} `json:"transfer"`
+		} `json:"reversal"`
+		Original struct {
+			ID             int64 `json:"id"`
+			ReversalStatus struct {
+				Status          string `json:"status"`
+				ReversedAmount  string `json:"reversed_amount"`
+				RemainingAmount string `json:"remaining_amount"`
+			} `json:"reversal_status"`
+		} `json:"original"`
+	}
+	err := json.Unmarshal(body.Bytes(), &gotResult)
+	assert.NoError(t, err)
+
+	reversal := gotResult.Reversal.Transfer
+	assert.Equal(t, amount, reversal.Amount)
+	assert.Equal(t, gotResult.Original.ID, reversal.ReversesTransferID)
+	assert.Equal(t, db.ReversalReasonDuplicate, reversal.ReversalReason)
+	// a reversal cannot be reversed, so it has no status of its own
+	assert.Nil(t, reversal.ReversalStatus)
+
+	assert.Equal(t, status, gotResult.Original.ReversalStatus.Status)
+	assert.Equal(t, amount, gotResult.Original.ReversalStatus.ReversedAmount)
+	assert.Equal(t, remaining, gotResult.Original.ReversalStatus.RemainingAmount)
+}
@@ -4,43 +4,62 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	mockdb "github.com/drmanalo/simplebank/db/mock"
 	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/token"
 	"github.com/drmanalo/simplebank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateTransferAPI(t *testing.T) {
-	account1 := randomAccount()
-	account2 := randomAccount()
-	account3 := randomAccount()
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user3.Username)
+	account4 := randomAccount(user3.Username)
 
 	account1.Currency = util.GBP
 	account2.Currency = util.GBP
 	account3.Currency = util.USD
+	account4.Currency = util.CAD
 
 	amount := int64(10)
+	amountValue := money.New(amount, util.GBP).String()
+	executeAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
 
 	testCases := []struct {
 		name          string
 		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "FromAccountNotFound",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
@@ -53,11 +72,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "FromAccountCurrencyMismatch",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account3.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
@@ -67,14 +89,55 @@ func TestCreateTransferAPI(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "ToAccountNotFound",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.TransferTxParams{
 					Amount:        amount,
@@ -92,11 +155,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "ToAccountcurrencyMismatch",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account3.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.TransferTxParams{
 					Amount:        amount,
@@ -114,11 +180,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "GetAccountError",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
@@ -130,11 +199,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "InvalidCurrency",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        "PHP",
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(0)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
@@ -146,11 +218,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "NegativeAmount",
 			body: gin.H{
-				"amount":          -amount,
+				"amount":          "-" + amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(0)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
@@ -162,11 +237,14 @@ func TestCreateTransferAPI(t *testing.T) {
 		{
 			name: "OK",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.TransferTxParams{
 					Amount:        amount,
@@ -175,20 +253,235 @@ func TestCreateTransferAPI(t *testing.T) {
 				}
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(newTransferTxResult(account1, account2, amount, amount, money.OneRate), nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusCreated, recorder.Code)
+				assertBodyMatchTransferAmounts(t, recorder.Body, "0.10", "0.10")
+			},
+		},
+		{
+			name: "Scheduled",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"execute_at":      executeAt,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateScheduledTransferTxParams{
+					CreateScheduledTransferParams: db.CreateScheduledTransferParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						ExecuteAt:     executeAt,
+					},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ScheduledTransfer{
+						ID:            1,
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						ExecuteAt:     executeAt,
+						Status:        db.ScheduledTransferPending,
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got map[string]interface{}
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				assert.Equal(t, amountValue, got["amount"])
+				assert.Equal(t, db.ScheduledTransferPending, got["status"])
+				assert.Equal(t, executeAt.Format(time.RFC3339), got["execute_at"])
+			},
+		},
+		{
+			name: "ScheduledCrossCurrency",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account4.ID,
+				"cross_currency":  true,
+				"execute_at":      executeAt,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// the rate is looked up when it executes, so a missing rate doesn't stop it being scheduled
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account4.ID)).Times(1).Return(account4, nil)
+				store.EXPECT().
+					CreateScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{ID: 1, Amount: amount, Status: db.ScheduledTransferPending}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "ExecuteAtInPast",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"execute_at":      time.Now().Add(-time.Minute),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrency",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"cross_currency":  true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferTxParams{
+					Amount:        amount,
+					FromAccountID: account1.ID,
+					ToAccountID:   account3.ID,
+					ToAmount:      12,
+					ExchangeRate:  money.MustParseRate("1.25"),
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(newTransferTxResult(account1, account3, amount, 12, money.MustParseRate("1.25")), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+				// 0.125 rounds half to even
+				assertBodyMatchTransferAmounts(t, recorder.Body, "0.10", "0.12")
+			},
+		},
+		{
+			name: "CrossCurrencyRateNotFound",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account4.ID,
+				"cross_currency":  true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account4.ID)).Times(1).Return(account4, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrencyFromAccountMismatch",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.USD,
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"cross_currency":  true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountFrozen",
+			body: gin.H{
+				"amount":          amountValue,
+				"currency":        util.GBP,
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				err := &db.AccountStatusError{AccountID: account2.ID, Status: db.AccountStatusFrozen}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, err)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
 		},
 		{
 			name: "TransferTxError",
 			body: gin.H{
-				"amount":          amount,
+				"amount":          amountValue,
 				"currency":        util.GBP,
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.TransferTxParams{
 					Amount:        amount,
@@ -215,7 +508,7 @@ func TestCreateTransferAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store, nil)
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -225,8 +518,468 @@ func TestCreateTransferAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			assert.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(recorder)
 		})
 	}
 }
+
+func TestGetTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	transfer := db.GetTransferDetailsRow{
+		Transfer: db.Transfer{
+			ID:            util.RandomInt(1, 1000),
+			FromAccountID: util.RandomInt(1, 1000),
+			ToAccountID:   util.RandomInt(1, 1000),
+			Amount:        1000,
+			ToAmount:      1250,
+			ExchangeRate:  money.MustParseRate("1.25"),
+		},
+		FromOwner:    user1.Username,
+		FromCurrency: "GBP",
+		ToOwner:      user2.Username,
+		ToCurrency:   "USD",
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "Sender",
+			transferID: transfer.Transfer.ID,
+			username:   user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				resp := newTransferResponse(transfer.Transfer, "GBP", "USD")
+				resp.ReversalStatus = newReversalStatusResponse(transfer.ReversalStatus(), "USD")
+
+				expected, err := json.Marshal(resp)
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(expected), recorder.Body.String())
+			},
+		},
+		{
+			name:       "Recipient",
+			transferID: transfer.Transfer.ID,
+			username:   user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				assert.NoError(t, err)
+				assert.Equal(t, "10.00", got["amount"])
+				assert.Equal(t, "12.50", got["to_amount"])
+			},
+		},
+		{
+			name:       "UnauthorizedUser",
+			transferID: transfer.Transfer.ID,
+			username:   "unauthorized_user",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.Transfer.ID,
+			username:   user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Eq(transfer.Transfer.ID)).
+					Times(1).
+					Return(db.GetTransferDetailsRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalError",
+			transferID: transfer.Transfer.ID,
+			username:   user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetTransferDetailsRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			username:   user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferDetails(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d", tc.transferID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "USD"
+
+	n := 5
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]db.ListUserTransfersNewestFirstRow, n+1)
+	for i := range rows {
+		rows[i] = db.ListUserTransfersNewestFirstRow{
+			Transfer: db.Transfer{
+				ID:            int64(n + 1 - i),
+				FromAccountID: account.ID,
+				ToAccountID:   util.RandomInt(1, 1000),
+				Amount:        int64(100 * (i + 1)),
+				ToAmount:      int64(100 * (i + 1)),
+				ExchangeRate:  money.OneRate,
+				CreatedAt:     pgtype.Timestamptz{Time: createdAt.Add(-time.Duration(i) * time.Minute), Valid: true},
+			},
+			FromCurrency: "USD",
+			ToCurrency:   "USD",
+		}
+	}
+
+	from := createdAt.AddDate(0, -1, 0)
+	after := pagination.Cursor{CreatedAt: createdAt, ID: 42}
+
+	testCases := []struct {
+		name          string
+		query         func(server *Server) url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NewestFirst",
+			query: func(server *Server) url.Values {
+				return url.Values{"page_size": {fmt.Sprint(n)}}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListUserTransfersNewestFirstParams{
+					Owner:    user.Username,
+					RowLimit: int32(n + 1),
+				}
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp struct {
+					Transfers  []json.RawMessage `json:"transfers"`
+					NextCursor string            `json:"next_cursor"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Len(t, resp.Transfers, n)
+
+				next, err := server.cursorSigner.Decode(pagination.TransfersScope(user.Username, true), resp.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, rows[n-1].Transfer.ID, next.ID)
+			},
+		},
+		{
+			name: "Filters",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"account_id": {fmt.Sprint(account.ID)},
+					"direction":  {"outgoing"},
+					"currency":   {"USD"},
+					"min_amount": {"1.50"},
+					"max_amount": {"20"},
+					"from":       {from.Format(time.RFC3339)},
+					"to":         {createdAt.Format(time.RFC3339)},
+					"sort":       {"oldest"},
+					"page_size":  {fmt.Sprint(n)},
+					"cursor":     {server.cursorSigner.Encode(pagination.TransfersScope(user.Username, false), after)},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				arg := db.ListUserTransfersOldestFirstParams{
+					Owner:          user.Username,
+					Direction:      pgtype.Text{String: "outgoing", Valid: true},
+					AccountID:      pgtype.Int8{Int64: account.ID, Valid: true},
+					Currency:       pgtype.Text{String: "USD", Valid: true},
+					MinAmount:      pgtype.Int8{Int64: 150, Valid: true},
+					MaxAmount:      pgtype.Int8{Int64: 2000, Valid: true},
+					FromTime:       pgtype.Timestamptz{Time: from, Valid: true},
+					ToTime:         pgtype.Timestamptz{Time: createdAt, Valid: true},
+					AfterCreatedAt: pgtype.Timestamptz{Time: after.CreatedAt, Valid: true},
+					AfterID:        pgtype.Int8{Int64: after.ID, Valid: true},
+					RowLimit:       int32(n + 1),
+				}
+				store.EXPECT().
+					ListUserTransfersOldestFirst(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.ListUserTransfersOldestFirstRow{db.ListUserTransfersOldestFirstRow(rows[0])}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				expected, err := json.Marshal(listTransfersResponse{
+					Transfers: []transferResponse{newTransferResponse(rows[0].Transfer, "USD", "USD")},
+				})
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(expected), recorder.Body.String())
+			},
+		},
+		{
+			name: "CursorFromOtherSortOrder",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"cursor":    {server.cursorSigner.Encode(pagination.TransfersScope(user.Username, false), after)},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AmountWithoutCurrency",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size":  {fmt.Sprint(n)},
+					"min_amount": {"1.00"},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size":  {fmt.Sprint(n)},
+					"currency":   {"JPY"},
+					"max_amount": {"1.5"},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDirection",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"direction": {"sideways"},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ToBeforeFrom",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size": {fmt.Sprint(n)},
+					"from":      {createdAt.Format(time.RFC3339)},
+					"to":        {from.Format(time.RFC3339)},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUsersAccount",
+			query: func(server *Server) url.Values {
+				return url.Values{
+					"page_size":  {fmt.Sprint(n)},
+					"account_id": {fmt.Sprint(account.ID)},
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = "someone_else"
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: func(server *Server) url.Values {
+				return url.Values{"page_size": {fmt.Sprint(n)}}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfersNewestFirst(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListUserTransfersNewestFirstRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers", nil)
+			assert.NoError(t, err)
+			request.URL.RawQuery = tc.query(server).Encode()
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func newTransferTxResult(fromAccount, toAccount db.Account, amount, toAmount int64, rate money.Rate) db.TransferTxResult {
+	return db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:            util.RandomInt(1, 1000),
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			ToAmount:      toAmount,
+			ExchangeRate:  rate,
+		},
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		FromEntry: db.Entry{
+			ID:        util.RandomInt(1, 1000),
+			AccountID: fromAccount.ID,
+			Amount:    -amount,
+		},
+		ToEntry: db.Entry{
+			ID:        util.RandomInt(1, 1000),
+			AccountID: toAccount.ID,
+			Amount:    toAmount,
+		},
+	}
+}
+
+func assertBodyMatchTransferAmounts(t *testing.T, body *bytes.Buffer, amount string, toAmount string) {
+	var gotResult struct {
+		Transfer struct {
+			Amount   string `json:"amount"`
+			ToAmount string `json:"to_amount"`
+		} `json:"transfer"`
+		FromEntry struct {
+			Amount string `json:"amount"`
+		} `json:"from_entry"`
+		ToEntry struct {
+			Amount string `json:"amount"`
+		} `json:"to_entry"`
+	}
+	err := json.Unmarshal(body.Bytes(), &gotResult)
+	assert.NoError(t, err)
+
+	assert.Equal(t, amount, gotResult.Transfer.Amount)
+	assert.Equal(t, toAmount, gotResult.Transfer.ToAmount)
+	assert.Equal(t, "-"+amount, gotResult.FromEntry.Amount)
+	assert.Equal(t, toAmount, gotResult.ToEntry.Amount)
+}
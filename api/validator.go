@@ -1,13 +1,15 @@
 package api
 
 import (
-	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/registry"
 	"github.com/go-playground/validator/v10"
 )
 
-var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(currency)
+func validCurrency(currencies *registry.CurrencyRegistry) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if currency, ok := fieldLevel.Field().Interface().(string); ok {
+			return currencies.IsSupported(currency)
+		}
+		return false
 	}
-	return false
 }
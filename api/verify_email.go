@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type verifyEmailRequest struct {
+	ID         int64  `form:"id" binding:"required,min=1"`
+	SecretCode string `form:"secret_code" binding:"required,len=32"`
+}
+
+type verifyEmailResponse struct {
+	IsVerified bool `json:"is_verified"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	txResult, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		EmailID:    req.ID,
+		SecretCode: req.SecretCode,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := errors.New("verify email is invalid, used or expired")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := verifyEmailResponse{
+		IsVerified: txResult.User.IsEmailVerified,
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,61 @@
+// Command ledgercheck verifies that the double-entry ledger balances.
+// It writes a JSON report to stdout and exits with status 1 if anything doesn't balance,
+// or 2 if the check could not run, so it can be scheduled as a nightly job
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory containing app.env")
+	batchSize := flag.Int("batch-size", 1000, "rows read per query")
+	flag.Parse()
+
+	report, err := checkLedger(context.Background(), *configPath, int32(*batchSize))
+	if err != nil {
+		log.Error().Err(err).Msg("cannot check ledger")
+		os.Exit(2)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Error().Err(err).Msg("cannot write report")
+		os.Exit(2)
+	}
+
+	if !report.OK() {
+		log.Error().
+			Int("balance_mismatches", len(report.BalanceMismatches)).
+			Int("transfer_mismatches", len(report.TransferMismatches)).
+			Int("orphan_entries", len(report.OrphanEntries)).
+			Msg("ledger doesn't balance")
+		os.Exit(1)
+	}
+}
+
+func checkLedger(ctx context.Context, configPath string, batchSize int32) (db.LedgerReport, error) {
+	config, err := util.LoadConfig(configPath)
+	if err != nil {
+		return db.LedgerReport{}, fmt.Errorf("cannot load config: %w", err)
+	}
+
+	connPool, err := pgxpool.New(ctx, config.DBSource)
+	if err != nil {
+		return db.LedgerReport{}, fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer connPool.Close()
+
+	store := db.NewStore(connPool)
+	return store.CheckLedger(ctx, db.CheckLedgerParams{BatchSize: batchSize})
+}
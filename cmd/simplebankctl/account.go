@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type accountView struct {
+	ID             int64              `json:"id"`
+	Owner          string             `json:"owner"`
+	Balance        money.Amount       `json:"balance"`
+	Currency       string             `json:"currency"`
+	OverdraftLimit money.Amount       `json:"overdraft_limit"`
+	Status         string             `json:"status"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+func newAccountView(account db.Account) accountView {
+	return accountView{
+		ID:             account.ID,
+		Owner:          account.Owner,
+		Balance:        money.New(account.Balance, account.Currency),
+		Currency:       account.Currency,
+		OverdraftLimit: money.New(account.OverdraftLimit, account.Currency),
+		Status:         account.Status,
+		CreatedAt:      account.CreatedAt,
+	}
+}
+
+func setupAccountOpen(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	owner := flags.String("owner", "", "username of the account holder")
+	currency := flags.String("currency", "", "currency code, e.g. USD")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *owner == "" {
+			return missingFlag("owner")
+		}
+		if *currency == "" {
+			return missingFlag("currency")
+		}
+
+		if err := cli.confirm("Open a %s account for %s?", *currency, *owner); err != nil {
+			return err
+		}
+
+		result, err := cli.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+			CreateAccountParams: db.CreateAccountParams{
+				Owner:    *owner,
+				Currency: *currency,
+				Balance:  0,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("cannot open account: %w", err)
+		}
+
+		return cli.print(newAccountView(result.Account))
+	}
+}
+
+func setupAccountShow(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	id := flags.Int64("id", 0, "account ID")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *id < 1 {
+			return missingFlag("id")
+		}
+
+		account, err := cli.store.GetAccount(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("cannot get account: %w", err)
+		}
+
+		return cli.print(newAccountView(account))
+	}
+}
+
+func setupAccountFreeze(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	id := flags.Int64("id", 0, "account ID")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *id < 1 {
+			return missingFlag("id")
+		}
+
+		if err := cli.confirm("Freeze account %d?", *id); err != nil {
+			return err
+		}
+
+		result, err := cli.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+			AccountID: *id,
+			Status:    db.AccountStatusFrozen,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot freeze account: %w", err)
+		}
+
+		return cli.print(newAccountView(result.Account))
+	}
+}
+
+func setupAccountAdjust(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	id := flags.Int64("id", 0, "account ID")
+	amount := flags.String("amount", "", "decimal amount to add in the account currency; negative to debit")
+	reason := flags.String("reason", "", "why the balance is being corrected")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *id < 1 {
+			return missingFlag("id")
+		}
+		if *amount == "" {
+			return missingFlag("amount")
+		}
+		if *reason == "" {
+			return missingFlag("reason")
+		}
+
+		account, err := cli.store.GetAccount(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("cannot get account: %w", err)
+		}
+
+		adjustment, err := money.Parse(*amount, account.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: --amount %s", errUsage, err)
+		}
+		if adjustment.Minor == 0 {
+			return fmt.Errorf("%w: --amount must not be zero", errUsage)
+		}
+
+		if err := cli.confirm("Adjust account %d by %s %s (%s)?", account.ID, adjustment, account.Currency, *reason); err != nil {
+			return err
+		}
+
+		result, err := cli.store.AdjustBalanceTx(ctx, db.AdjustBalanceTxParams{
+			AccountID: account.ID,
+			Amount:    adjustment.Minor,
+			Reason:    *reason,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot adjust balance: %w", err)
+		}
+
+		return cli.print(struct {
+			accountView
+			AdjustmentID int64        `json:"adjustment_id"`
+			Adjustment   money.Amount `json:"adjustment"`
+			Reason       string       `json:"reason"`
+		}{
+			newAccountView(result.Account),
+			result.Adjustment.ID,
+			money.New(result.Adjustment.Amount, account.Currency),
+			result.Adjustment.Reason,
+		})
+	}
+}
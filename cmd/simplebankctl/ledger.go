@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+)
+
+var errLedgerUnbalanced = errors.New("ledger doesn't balance")
+
+func setupLedgerCheck(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	batchSize := flags.Int("batch-size", 1000, "rows read per query")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *batchSize < 1 {
+			return fmt.Errorf("%w: --batch-size must be at least 1", errUsage)
+		}
+
+		report, err := cli.store.CheckLedger(ctx, db.CheckLedgerParams{BatchSize: int32(*batchSize)})
+		if err != nil {
+			return fmt.Errorf("cannot check ledger: %w", err)
+		}
+
+		if cli.output == outputJSON {
+			err = cli.print(report)
+		} else {
+			err = cli.printTable(ledgerReportRows(report))
+		}
+		if err != nil {
+			return err
+		}
+
+		if !report.OK() {
+			return errLedgerUnbalanced
+		}
+		return nil
+	}
+}
+
+// ledgerReportRows lists one row per problem after a summary of what was checked
+func ledgerReportRows(report db.LedgerReport) [][]string {
+	rows := [][]string{
+		{"accounts_checked", fmt.Sprint(report.AccountsChecked)},
+		{"transfers_checked", fmt.Sprint(report.TransfersChecked)},
+		{"problems", fmt.Sprint(len(report.BalanceMismatches) + len(report.TransferMismatches) + len(report.OrphanEntries))},
+	}
+
+	if report.OK() {
+		return rows
+	}
+
+	rows = append(rows, []string{}, []string{"PROBLEM", "ID", "DETAIL"})
+	for _, mismatch := range report.BalanceMismatches {
+		detail := fmt.Sprintf("balance %d, entries total %d", mismatch.Balance, mismatch.EntriesTotal)
+		rows = append(rows, []string{"account_balance", fmt.Sprint(mismatch.AccountID), detail})
+	}
+	for _, mismatch := range report.TransferMismatches {
+		detail := fmt.Sprintf("%d entries", mismatch.EntryCount)
+		rows = append(rows, []string{"transfer_" + mismatch.Problem, fmt.Sprint(mismatch.TransferID), detail})
+	}
+	for _, entry := range report.OrphanEntries {
+		detail := fmt.Sprintf("account %d, amount %d", entry.AccountID, entry.Amount)
+		rows = append(rows, []string{"orphan_entry", fmt.Sprint(entry.ID), detail})
+	}
+	return rows
+}
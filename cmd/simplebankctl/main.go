@@ -0,0 +1,173 @@
+// Command simplebankctl lets operators manage users, accounts, transfers, the ledger
+// and the database schema directly, without going through the API.
+//
+// Usage:
+//
+//	simplebankctl <group> <command> [flags]
+//
+// Every command accepts --config, --output json|table and --yes.
+// Commands that change data ask for confirmation first unless --yes is given
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+var (
+	errUsage   = errors.New("usage")
+	errAborted = errors.New("aborted")
+)
+
+// command is a subcommand such as "account show".
+// setup registers its flags and returns the function that runs it once they are parsed
+type command struct {
+	summary string
+	setup   func(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error
+}
+
+var commands = map[string]map[string]command{
+	"user": {
+		"create":  {"create a user", setupUserCreate},
+		"show":    {"show a user", setupUserShow},
+		"disable": {"stop a user from logging in and block their sessions", setupUserDisable},
+	},
+	"account": {
+		"open":   {"open an account for a user", setupAccountOpen},
+		"show":   {"show an account", setupAccountShow},
+		"freeze": {"freeze an account", setupAccountFreeze},
+		"adjust": {"correct an account balance outside of a transfer", setupAccountAdjust},
+	},
+	"transfer": {
+		"show":    {"show a transfer", setupTransferShow},
+		"reverse": {"pay a transfer back, in full or in part", setupTransferReverse},
+	},
+	"ledger": {
+		"check": {"verify that the double-entry ledger balances", setupLedgerCheck},
+	},
+	"migrate": {
+		"up":     {"apply all pending migrations", setupMigrateUp},
+		"down":   {"roll back migrations", setupMigrateDown},
+		"status": {"show the current schema version", setupMigrateStatus},
+	},
+}
+
+// cli holds what every command needs once the common flags are parsed
+type cli struct {
+	config util.Config
+	store  db.Store
+	stdin  *bufio.Reader
+	stdout io.Writer
+	output string
+	yes    bool
+}
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "error:", err)
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage())
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// run parses args and runs the command they name
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	group, name := args[0], args[1]
+	cmd, ok := commands[group][name]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, group+" "+name)
+	}
+
+	flags := flag.NewFlagSet(group+" "+name, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	configPath := flags.String("config", ".", "directory containing app.env")
+	output := flags.String("output", outputTable, "output format: json or table")
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	runCommand := cmd.setup(flags)
+
+	if err := flags.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	if *output != outputJSON && *output != outputTable {
+		return fmt.Errorf("%w: --output must be json or table", errUsage)
+	}
+
+	config, err := util.LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	// the pool only connects when the first query runs, so migrate commands never use it
+	connPool, err := pgxpool.New(ctx, config.DBSource)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer connPool.Close()
+
+	return runCommand(ctx, &cli{
+		config: config,
+		store:  db.NewStore(connPool),
+		stdin:  bufio.NewReader(stdin),
+		stdout: stdout,
+		output: *output,
+		yes:    *yes,
+	})
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: simplebankctl <group> <command> [flags]\n\ncommands:\n")
+
+	groups := make([]string, 0, len(commands))
+	for group := range commands {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		names := make([]string, 0, len(commands[group]))
+		for name := range commands[group] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %-18s %s\n", group+" "+name, commands[group][name].summary)
+		}
+	}
+
+	b.WriteString("\nrun a command with -h to see its flags\n")
+	return b.String()
+}
+
+// missingFlag is the usage error for a required flag that was left out
+func missingFlag(name string) error {
+	return fmt.Errorf("%w: --%s is required", errUsage, name)
+}
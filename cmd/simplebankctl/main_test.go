@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore points at the same Postgres as the db/sqlc tests, for setting up data the CLI can't create
+var testStore db.Store
+
+func TestMain(m *testing.M) {
+	config, err := util.LoadConfig("../..")
+	if err != nil {
+		log.Fatal("cannot load config", err)
+	}
+
+	connPool, err := pgxpool.New(context.Background(), config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db", err)
+	}
+
+	testStore = db.NewStore(connPool)
+	os.Exit(m.Run())
+}
+
+// runCLI runs simplebankctl against the test database with the answers on stdin
+func runCLI(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	args = append(args, "--config", "../..")
+	err := run(context.Background(), args, strings.NewReader(stdin), &stdout)
+	return stdout.String(), err
+}
+
+// runJSON runs a command with --yes and decodes its JSON output
+func runJSON(t *testing.T, v interface{}, args ...string) {
+	out, err := runCLI(t, "", append(args, "--yes", "--output", "json")...)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal([]byte(out), v), out)
+}
+
+func createUser(t *testing.T, role string) string {
+	username := util.RandomOwner()
+
+	var user struct {
+		Username string `json:"username"`
+		Role     string `json:"role"`
+		Password string `json:"password"`
+	}
+	runJSON(t, &user, "user", "create",
+		"--username", username,
+		"--full-name", util.RandomOwner(),
+		"--email", util.RandomEmail(),
+		"--role", role,
+	)
+	assert.Equal(t, username, user.Username)
+	assert.Equal(t, role, user.Role)
+	// the password is generated and shown once
+	assert.Len(t, user.Password, 16)
+
+	return username
+}
+
+func TestUserCommands(t *testing.T) {
+	username := createUser(t, util.BankerRole)
+
+	out, err := runCLI(t, "", "user", "show", "--username", username)
+	assert.NoError(t, err)
+	assert.Contains(t, out, username)
+	assert.Contains(t, out, util.BankerRole)
+	assert.NotContains(t, out, "password")
+
+	var user struct {
+		IsDisabled      bool  `json:"is_disabled"`
+		BlockedSessions int64 `json:"blocked_sessions"`
+	}
+	runJSON(t, &user, "user", "disable", "--username", username)
+	assert.True(t, user.IsDisabled)
+	assert.Zero(t, user.BlockedSessions)
+}
+
+func TestAccountCommands(t *testing.T) {
+	owner := createUser(t, util.DepositorRole)
+
+	var account struct {
+		ID      int64  `json:"id"`
+		Balance string `json:"balance"`
+		Status  string `json:"status"`
+	}
+	runJSON(t, &account, "account", "open", "--owner", owner, "--currency", util.USD)
+	assert.Equal(t, "0.00", account.Balance)
+
+	id := strconv.FormatInt(account.ID, 10)
+
+	runJSON(t, &account, "account", "adjust", "--id", id, "--amount", "12.50", "--reason", "opening deposit")
+	assert.Equal(t, "12.50", account.Balance)
+
+	runJSON(t, &account, "account", "freeze", "--id", id)
+	assert.Equal(t, db.AccountStatusFrozen, account.Status)
+
+	out, err := runCLI(t, "", "account", "show", "--id", id)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "12.50")
+	assert.Contains(t, out, db.AccountStatusFrozen)
+
+	// the adjustment keeps the account balanced
+	report, err := testStore.CheckLedger(context.Background(), db.CheckLedgerParams{BatchSize: 1000})
+	assert.NoError(t, err)
+	for _, mismatch := range report.BalanceMismatches {
+		assert.NotEqual(t, account.ID, mismatch.AccountID)
+	}
+}
+
+func TestTransferShow(t *testing.T) {
+	var accounts [2]struct {
+		ID int64 `json:"id"`
+	}
+	for i := range accounts {
+		runJSON(t, &accounts[i], "account", "open", "--owner", createUser(t, util.DepositorRole), "--currency", util.EUR)
+	}
+
+	_, err := testStore.AdjustBalanceTx(context.Background(), db.AdjustBalanceTxParams{
+		AccountID: accounts[0].ID,
+		Amount:    1000,
+		Reason:    "test funds",
+	})
+	assert.NoError(t, err)
+
+	result, err := testStore.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        250,
+	})
+	assert.NoError(t, err)
+
+	var transfer struct {
+		ID       int64  `json:"id"`
+		Amount   string `json:"amount"`
+		ToAmount string `json:"to_amount"`
+	}
+	runJSON(t, &transfer, "transfer", "show", "--id", strconv.FormatInt(result.Transfer.ID, 10))
+	assert.Equal(t, result.Transfer.ID, transfer.ID)
+	assert.Equal(t, "2.50", transfer.Amount)
+	assert.Equal(t, "2.50", transfer.ToAmount)
+}
+
+func TestTransferReverse(t *testing.T) {
+	var accounts [2]struct {
+		ID int64 `json:"id"`
+	}
+	for i := range accounts {
+		runJSON(t, &accounts[i], "account", "open", "--owner", createUser(t, util.DepositorRole), "--currency", util.USD)
+	}
+
+	_, err := testStore.AdjustBalanceTx(context.Background(), db.AdjustBalanceTxParams{
+		AccountID: accounts[1].ID,
+		Amount:    1000,
+		Reason:    "test funds",
+	})
+	assert.NoError(t, err)
+
+	result, err := testStore.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: accounts[1].ID,
+		ToAccountID:   accounts[0].ID,
+		Amount:        1000,
+	})
+	assert.NoError(t, err)
+	id := strconv.FormatInt(result.Transfer.ID, 10)
+
+	var reversal struct {
+		Amount                  string `json:"amount"`
+		ReversesTransferID      int64  `json:"reverses_transfer_id"`
+		ReversalReason          string `json:"reversal_reason"`
+		OriginalStatus          string `json:"original_status"`
+		OriginalRemainingAmount string `json:"original_remaining_amount"`
+	}
+	runJSON(t, &reversal, "transfer", "reverse", "--id", id, "--amount", "4.00", "--reason", db.ReversalReasonDuplicate)
+	assert.Equal(t, "4.00", reversal.Amount)
+	assert.Equal(t, result.Transfer.ID, reversal.ReversesTransferID)
+	assert.Equal(t, db.ReversalReasonDuplicate, reversal.ReversalReason)
+	assert.Equal(t, db.ReversalStatusPartial, reversal.OriginalStatus)
+	assert.Equal(t, "6.00", reversal.OriginalRemainingAmount)
+
+	runJSON(t, &reversal, "transfer", "reverse", "--id", id, "--reason", db.ReversalReasonDuplicate)
+	assert.Equal(t, "6.00", reversal.Amount)
+	assert.Equal(t, db.ReversalStatusFull, reversal.OriginalStatus)
+
+	out, err := runCLI(t, "", "transfer", "show", "--id", id)
+	assert.NoError(t, err)
+	assert.Contains(t, out, db.ReversalStatusFull)
+	assert.Contains(t, out, "10.00")
+
+	_, err = runCLI(t, "", "transfer", "reverse", "--id", id, "--reason", db.ReversalReasonDuplicate, "--yes")
+	assert.ErrorIs(t, err, db.ErrTransferReversed)
+}
+
+func TestLedgerCheck(t *testing.T) {
+	out, err := runCLI(t, "", "ledger", "check", "--output", "json")
+	// other tests leave deliberate mismatches behind, so only the report itself is checked
+	if err != nil {
+		assert.ErrorIs(t, err, errLedgerUnbalanced)
+	}
+
+	var report db.LedgerReport
+	assert.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.NotZero(t, report.AccountsChecked)
+}
+
+func TestMigrateStatus(t *testing.T) {
+	var status migrateStatus
+	out, err := runCLI(t, "", "migrate", "status", "--output", "json")
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal([]byte(out), &status))
+	assert.NotZero(t, status.Version)
+	assert.False(t, status.Dirty)
+}
+
+func TestConfirmation(t *testing.T) {
+	username := createUser(t, util.DepositorRole)
+
+	for _, answer := range []string{"", "n\n", "nope\n"} {
+		out, err := runCLI(t, answer, "user", "disable", "--username", username)
+		assert.ErrorIs(t, err, errAborted)
+		assert.Contains(t, out, "[y/N]")
+	}
+
+	user, err := testStore.GetUser(context.Background(), username)
+	assert.NoError(t, err)
+	assert.False(t, user.IsDisabled)
+
+	_, err = runCLI(t, "y\n", "user", "disable", "--username", username)
+	assert.NoError(t, err)
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"account"},
+		{"account", "close"},
+		{"account", "show"},
+		{"account", "show", "--id", "1", "--output", "yaml"},
+		{"account", "adjust", "--id", "1", "--amount", "1.00"},
+		{"transfer", "reverse", "--id", "1"},
+		{"transfer", "reverse", "--id", "1", "--reason", "changed_my_mind"},
+	} {
+		_, err := runCLI(t, "", args...)
+		assert.ErrorIs(t, err, errUsage, args)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/drmanalo/simplebank/db/migration"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+type migrateStatus struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+}
+
+func setupMigrateUp(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	return func(ctx context.Context, cli *cli) error {
+		if err := cli.confirm("Apply all pending migrations?"); err != nil {
+			return err
+		}
+
+		return cli.migrate(func(m *migrate.Migrate) error {
+			return m.Up()
+		})
+	}
+}
+
+func setupMigrateDown(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *steps < 1 {
+			return fmt.Errorf("%w: --steps must be at least 1", errUsage)
+		}
+
+		if err := cli.confirm("Roll back %d migration(s)? This can drop tables and their data", *steps); err != nil {
+			return err
+		}
+
+		return cli.migrate(func(m *migrate.Migrate) error {
+			return m.Steps(-*steps)
+		})
+	}
+}
+
+func setupMigrateStatus(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	return func(ctx context.Context, cli *cli) error {
+		return cli.migrate(func(m *migrate.Migrate) error {
+			return nil
+		})
+	}
+}
+
+// migrate runs fn against the embedded migrations and prints the schema version it leaves behind
+func (cli *cli) migrate(fn func(m *migrate.Migrate) error) error {
+	m, err := migration.New(cli.config.DBSource, cli.config.MigrationLockTimeout)
+	if err != nil {
+		return fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+	defer m.Close()
+
+	err = fn(m)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("cannot migrate: %w", err)
+	}
+
+	var status migrateStatus
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("cannot get schema version: %w", err)
+	}
+
+	return cli.print(status)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// print writes a struct in the --output format.
+// Tables list one field per row, labelled with the field's JSON name
+func (cli *cli) print(value interface{}) error {
+	if cli.output == outputJSON {
+		encoder := json.NewEncoder(cli.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	return cli.printTable(fieldRows(value))
+}
+
+// printTable writes the rows as aligned columns
+func (cli *cli) printTable(rows [][]string) error {
+	w := tabwriter.NewWriter(cli.stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// confirm asks the operator to go ahead with a change, unless --yes was given.
+// Anything but y or yes aborts
+func (cli *cli) confirm(format string, args ...interface{}) error {
+	if cli.yes {
+		return nil
+	}
+
+	fmt.Fprintf(cli.stdout, format+" [y/N] ", args...)
+	answer, err := cli.stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return errAborted
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+// fieldRows turns the exported fields of a struct into name and value rows
+func fieldRows(value interface{}) [][]string {
+	return structRows(reflect.Indirect(reflect.ValueOf(value)))
+}
+
+func structRows(v reflect.Value) [][]string {
+	t := v.Type()
+
+	var rows [][]string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// embedded structs add their fields, as they do in JSON
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			rows = append(rows, structRows(v.Field(i))...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		rows = append(rows, []string{name, formatValue(v.Field(i).Interface())})
+	}
+	return rows
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case pgtype.Timestamptz:
+		if !v.Valid {
+			return ""
+		}
+		return v.Time.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type transferView struct {
+	ID            int64              `json:"id"`
+	FromAccountID int64              `json:"from_account_id"`
+	FromOwner     string             `json:"from_owner"`
+	ToAccountID   int64              `json:"to_account_id"`
+	ToOwner       string             `json:"to_owner"`
+	Amount        money.Amount       `json:"amount"`
+	FromCurrency  string             `json:"from_currency"`
+	ToAmount      money.Amount       `json:"to_amount"`
+	ToCurrency    string             `json:"to_currency"`
+	ExchangeRate  money.Rate         `json:"exchange_rate"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	// reversals link back to the transfer they reverse, and other transfers show how much was reversed
+	ReversesTransferID int64  `json:"reverses_transfer_id,omitempty"`
+	ReversalReason     string `json:"reversal_reason,omitempty"`
+	ReversalStatus     string `json:"reversal_status,omitempty"`
+	ReversedAmount     string `json:"reversed_amount,omitempty"`
+}
+
+func newTransferView(transfer db.GetTransferDetailsRow) transferView {
+	view := transferView{
+		ID:                 transfer.Transfer.ID,
+		FromAccountID:      transfer.Transfer.FromAccountID,
+		FromOwner:          transfer.FromOwner,
+		ToAccountID:        transfer.Transfer.ToAccountID,
+		ToOwner:            transfer.ToOwner,
+		Amount:             money.New(transfer.Transfer.Amount, transfer.FromCurrency),
+		FromCurrency:       transfer.FromCurrency,
+		ToAmount:           money.New(transfer.Transfer.ToAmount, transfer.ToCurrency),
+		ToCurrency:         transfer.ToCurrency,
+		ExchangeRate:       transfer.Transfer.ExchangeRate,
+		CreatedAt:          transfer.Transfer.CreatedAt,
+		ReversesTransferID: transfer.Transfer.ReversesTransferID.Int64,
+		ReversalReason:     transfer.Transfer.ReversalReason.String,
+	}
+
+	if status := transfer.ReversalStatus(); status != nil {
+		view.ReversalStatus = status.Status
+		view.ReversedAmount = money.New(status.ReversedAmount, transfer.ToCurrency).String()
+	}
+
+	return view
+}
+
+func setupTransferShow(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	id := flags.Int64("id", 0, "transfer ID")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *id < 1 {
+			return missingFlag("id")
+		}
+
+		transfer, err := cli.store.GetTransferDetails(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("cannot get transfer: %w", err)
+		}
+
+		return cli.print(newTransferView(transfer))
+	}
+}
+
+func setupTransferReverse(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	id := flags.Int64("id", 0, "ID of the transfer to reverse")
+	amount := flags.String("amount", "", "decimal amount in the to account currency; leave out to reverse what is left")
+	reason := flags.String("reason", "", "reason code: "+strings.Join(db.ReversalReasons, ", "))
+
+	return func(ctx context.Context, cli *cli) error {
+		if *id < 1 {
+			return missingFlag("id")
+		}
+		if !db.IsReversalReason(*reason) {
+			return fmt.Errorf("%w: --reason must be one of %s", errUsage, strings.Join(db.ReversalReasons, ", "))
+		}
+
+		transfer, err := cli.store.GetTransferDetails(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("cannot get transfer: %w", err)
+		}
+
+		arg := db.ReverseTransferTxParams{
+			TransferID: transfer.Transfer.ID,
+			Reason:     *reason,
+		}
+
+		description := "what is left of"
+		if *amount != "" {
+			reversal, err := money.Parse(*amount, transfer.ToCurrency)
+			if err != nil {
+				return fmt.Errorf("%w: --amount %s", errUsage, err)
+			}
+			if !reversal.IsPositive() {
+				return fmt.Errorf("%w: --amount must be greater than 0", errUsage)
+			}
+
+			arg.Amount = reversal.Minor
+			description = fmt.Sprintf("%s %s of", reversal, transfer.ToCurrency)
+		}
+
+		if err := cli.confirm("Reverse %s transfer %d from %s to %s (%s)?",
+			description, transfer.Transfer.ID, transfer.FromOwner, transfer.ToOwner, *reason); err != nil {
+			return err
+		}
+
+		result, err := cli.store.ReverseTransferTx(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("cannot reverse transfer: %w", err)
+		}
+
+		reversal, err := cli.store.GetTransferDetails(ctx, result.Reversal.Transfer.ID)
+		if err != nil {
+			return fmt.Errorf("cannot get reversal: %w", err)
+		}
+
+		return cli.print(struct {
+			transferView
+			OriginalStatus          string       `json:"original_status"`
+			OriginalRemainingAmount money.Amount `json:"original_remaining_amount"`
+		}{
+			newTransferView(reversal),
+			result.OriginalStatus.Status,
+			money.New(result.OriginalStatus.RemainingAmount, transfer.ToCurrency),
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/val"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// userView is a user without the password hash
+type userView struct {
+	Username        string             `json:"username"`
+	FullName        string             `json:"full_name"`
+	Email           string             `json:"email"`
+	Role            string             `json:"role"`
+	IsEmailVerified bool               `json:"is_email_verified"`
+	IsDisabled      bool               `json:"is_disabled"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+func newUserView(user db.User) userView {
+	return userView{
+		Username:        user.Username,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		Role:            user.Role,
+		IsEmailVerified: user.IsEmailVerified,
+		IsDisabled:      user.IsDisabled,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+func setupUserCreate(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	username := flags.String("username", "", "username")
+	fullName := flags.String("full-name", "", "full name")
+	email := flags.String("email", "", "email address")
+	password := flags.String("password", "", "password; a random one is generated and printed if left out")
+	role := flags.String("role", util.DepositorRole, "depositor or banker")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *role != util.DepositorRole && *role != util.BankerRole {
+			return fmt.Errorf("%w: --role must be %s or %s", errUsage, util.DepositorRole, util.BankerRole)
+		}
+
+		generated := *password == ""
+		if generated {
+			*password = util.RandomString(16)
+		}
+
+		for _, check := range []struct {
+			flag string
+			err  error
+		}{
+			{"username", val.ValidateUsername(*username)},
+			{"full-name", val.ValidateFullName(*fullName)},
+			{"email", val.ValidateEmail(*email)},
+			{"password", val.ValidatePassword(*password)},
+		} {
+			if check.err != nil {
+				return fmt.Errorf("%w: --%s %s", errUsage, check.flag, check.err)
+			}
+		}
+
+		if err := cli.confirm("Create %s %s <%s>?", *role, *username, *email); err != nil {
+			return err
+		}
+
+		hashedPassword, err := util.HashPassword(*password)
+		if err != nil {
+			return err
+		}
+
+		result, err := cli.store.CreateUserTx(ctx, db.CreateUserTxParams{
+			CreateUserParams: db.CreateUserParams{
+				Username:       *username,
+				HashedPassword: hashedPassword,
+				FullName:       *fullName,
+				Email:          *email,
+			},
+			Role: *role,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create user: %w", err)
+		}
+
+		if !generated {
+			return cli.print(newUserView(result.User))
+		}
+
+		return cli.print(struct {
+			userView
+			Password string `json:"password"`
+		}{newUserView(result.User), *password})
+	}
+}
+
+func setupUserShow(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	username := flags.String("username", "", "username")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *username == "" {
+			return missingFlag("username")
+		}
+
+		user, err := cli.store.GetUser(ctx, *username)
+		if err != nil {
+			return fmt.Errorf("cannot get user: %w", err)
+		}
+
+		return cli.print(newUserView(user))
+	}
+}
+
+func setupUserDisable(flags *flag.FlagSet) func(ctx context.Context, cli *cli) error {
+	username := flags.String("username", "", "username")
+
+	return func(ctx context.Context, cli *cli) error {
+		if *username == "" {
+			return missingFlag("username")
+		}
+
+		if err := cli.confirm("Disable %s and block all of their sessions?", *username); err != nil {
+			return err
+		}
+
+		result, err := cli.store.DisableUserTx(ctx, db.DisableUserTxParams{Username: *username})
+		if err != nil {
+			return fmt.Errorf("cannot disable user: %w", err)
+		}
+
+		return cli.print(struct {
+			userView
+			BlockedSessions int64 `json:"blocked_sessions"`
+		}{newUserView(result.User), result.BlockedSessions})
+	}
+}
@@ -0,0 +1,3 @@
+alter table if exists sessions drop column if exists last_used_at;
+
+drop index if exists sessions_username_idx;
\ No newline at end of file
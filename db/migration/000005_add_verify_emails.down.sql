@@ -0,0 +1,3 @@
+drop table if exists verify_emails cascade;
+
+alter table if exists users drop column if exists is_email_verified;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "request_path" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int NOT NULL,
+  "response_body" bytea NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("username", "key")
+);
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
@@ -0,0 +1 @@
+alter table if exists accounts drop column if exists overdraft_limit;
@@ -0,0 +1,5 @@
+alter table if exists transfers drop column if exists exchange_rate;
+
+alter table if exists transfers drop column if exists to_amount;
+
+comment on column transfers.amount is 'must be positive';
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric(20,10) NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the from account';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'must be positive, in the currency of the to account';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'units of the to currency for one unit of the from currency';
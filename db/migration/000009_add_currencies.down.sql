@@ -0,0 +1,3 @@
+alter table if exists accounts drop constraint if exists accounts_currency_fkey;
+
+drop table if exists currencies;
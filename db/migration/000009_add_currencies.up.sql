@@ -0,0 +1,20 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "exponent" int NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT false,
+  "display_name" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "currencies" ("code", "exponent", "enabled", "display_name") VALUES
+  ('AUD', 2, false, 'Australian Dollar'),
+  ('CAD', 2, true, 'Canadian Dollar'),
+  ('CHF', 2, false, 'Swiss Franc'),
+  ('EUR', 2, true, 'Euro'),
+  ('GBP', 2, true, 'Pound Sterling'),
+  ('JPY', 0, false, 'Yen'),
+  ('NZD', 2, false, 'New Zealand Dollar'),
+  ('SGD', 2, false, 'Singapore Dollar'),
+  ('USD', 2, true, 'US Dollar');
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,3 @@
+drop index if exists entries_account_id_created_at_id_idx;
+
+alter table if exists entries drop column if exists transfer_id;
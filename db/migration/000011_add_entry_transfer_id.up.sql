@@ -0,0 +1,18 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- TransferTx creates the transfer and its entries in one transaction, so they share created_at
+UPDATE "entries" AS e
+SET "transfer_id" = t."id"
+FROM "transfers" AS t
+WHERE e."transfer_id" IS NULL
+  AND e."created_at" = t."created_at"
+  AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount") OR
+    (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount")
+  );
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer that created the entry, if any';
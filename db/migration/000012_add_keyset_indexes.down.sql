@@ -0,0 +1,5 @@
+drop index if exists accounts_owner_created_at_id_idx;
+
+drop index if exists transfers_from_account_id_created_at_id_idx;
+
+drop index if exists transfers_to_account_id_created_at_id_idx;
@@ -0,0 +1,6 @@
+-- keyset pagination walks each list in (created_at, id) order
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
@@ -0,0 +1 @@
+drop index if exists transfers_created_at_id_idx;
@@ -0,0 +1,2 @@
+-- date range filters on transfer history scan by created_at
+CREATE INDEX ON "transfers" ("created_at", "id");
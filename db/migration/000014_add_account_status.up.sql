@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+COMMENT ON COLUMN "accounts"."status" IS 'frozen and closed accounts cannot send or receive transfers';
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "is_disabled" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "users"."is_disabled" IS 'disabled users cannot log in';
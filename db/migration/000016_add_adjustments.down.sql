@@ -0,0 +1,2 @@
+alter table if exists entries drop column if exists adjustment_id;
+drop table if exists adjustments;
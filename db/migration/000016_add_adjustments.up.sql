@@ -0,0 +1,19 @@
+CREATE TABLE "adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+COMMENT ON TABLE "adjustments" IS 'balance corrections made by an operator rather than a transfer';
+
+ALTER TABLE "entries" ADD COLUMN "adjustment_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("adjustment_id") REFERENCES "adjustments" ("id");
+
+COMMENT ON COLUMN "entries"."adjustment_id" IS 'the adjustment that created the entry, if any';
@@ -0,0 +1,2 @@
+alter table if exists transfers drop column if exists reversal_reason;
+alter table if exists transfers drop column if exists reverses_transfer_id;
@@ -0,0 +1,14 @@
+ALTER TABLE "transfers" ADD COLUMN "reverses_transfer_id" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversal_reason" varchar;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reverses_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reversal_reason_check" CHECK (
+  ("reverses_transfer_id" IS NULL AND "reversal_reason" IS NULL) OR
+  ("reverses_transfer_id" IS NOT NULL AND "reversal_reason" IN ('duplicate', 'fraudulent', 'requested_by_customer', 'processing_error'))
+);
+
+CREATE INDEX ON "transfers" ("reverses_transfer_id");
+
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'the transfer this one pays back, in full or in part';
@@ -0,0 +1,31 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "execute_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "failure_reason" varchar,
+  "processed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_status_check" CHECK ("status" IN ('pending', 'executed', 'failed', 'cancelled'));
+
+-- the scheduler only looks for pending transfers that are due
+CREATE INDEX ON "scheduled_transfers" ("execute_at", "id") WHERE "status" = 'pending';
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'in the currency of the from account; cross-currency transfers are converted when they execute';
+
+COMMENT ON COLUMN "scheduled_transfers"."transfer_id" IS 'the transfer made when it was executed';
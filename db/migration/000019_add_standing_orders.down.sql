@@ -0,0 +1,2 @@
+alter table if exists transfers drop column if exists standing_order_id;
+drop table if exists standing_orders;
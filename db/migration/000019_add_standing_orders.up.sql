@@ -0,0 +1,48 @@
+CREATE TABLE "standing_orders" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL,
+  "interval" int NOT NULL DEFAULT 1,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_occurrences" int,
+  "occurrences" int NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz,
+  "retry_count" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD CONSTRAINT "standing_orders_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "standing_orders" ADD CONSTRAINT "standing_orders_rule_check" CHECK (
+  "frequency" IN ('daily', 'weekly', 'monthly') AND "interval" > 0
+);
+
+ALTER TABLE "standing_orders" ADD CONSTRAINT "standing_orders_status_check" CHECK ("status" IN ('active', 'completed', 'cancelled'));
+
+-- the worker only looks for active orders that are due
+CREATE INDEX ON "standing_orders" ("next_run_at", "id") WHERE "status" = 'active';
+
+CREATE INDEX ON "standing_orders" ("from_account_id");
+
+COMMENT ON COLUMN "standing_orders"."occurrences" IS 'occurrences that were paid or given up on after their retries';
+
+COMMENT ON COLUMN "standing_orders"."next_run_at" IS 'the next occurrence, or the next retry of a failed one; null once the order has ended';
+
+COMMENT ON COLUMN "standing_orders"."retry_count" IS 'failed attempts at the current occurrence';
+
+ALTER TABLE "transfers" ADD COLUMN "standing_order_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id");
+
+CREATE INDEX ON "transfers" ("standing_order_id");
+
+COMMENT ON COLUMN "transfers"."standing_order_id" IS 'the standing order the transfer paid an occurrence of, if any';
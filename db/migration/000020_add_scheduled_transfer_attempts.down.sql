@@ -0,0 +1,5 @@
+drop index if exists scheduled_transfers_next_attempt_at_id_idx;
+create index if not exists scheduled_transfers_execute_at_id_idx on scheduled_transfers (execute_at, id) where status = 'pending';
+alter table if exists scheduled_transfers drop column if exists next_attempt_at;
+alter table if exists scheduled_transfers drop column if exists last_error;
+alter table if exists scheduled_transfers drop column if exists attempts;
@@ -0,0 +1,18 @@
+ALTER TABLE "scheduled_transfers" ADD COLUMN "attempts" int NOT NULL DEFAULT 0;
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "last_error" varchar;
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "next_attempt_at" timestamptz;
+
+UPDATE "scheduled_transfers" SET "next_attempt_at" = "execute_at";
+
+ALTER TABLE "scheduled_transfers" ALTER COLUMN "next_attempt_at" SET NOT NULL;
+
+-- the scheduler now looks for pending transfers whose next attempt is due
+DROP INDEX IF EXISTS "scheduled_transfers_execute_at_id_idx";
+
+CREATE INDEX ON "scheduled_transfers" ("next_attempt_at", "id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "scheduled_transfers"."attempts" IS 'how many times a scheduler has claimed the transfer';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_attempt_at" IS 'execute_at at first, then pushed back while a claimed attempt runs and after an attempt fails unexpectedly';
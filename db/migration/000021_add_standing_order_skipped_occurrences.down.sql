@@ -0,0 +1,2 @@
+alter table if exists standing_orders drop column if exists skipped_occurrences;
+comment on column standing_orders.occurrences is 'occurrences that were paid or given up on after their retries';
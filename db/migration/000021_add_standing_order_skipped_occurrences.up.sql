@@ -0,0 +1,6 @@
+ALTER TABLE "standing_orders" ADD COLUMN "skipped_occurrences" int NOT NULL DEFAULT 0;
+
+-- the occurrences of existing orders still include the ones given up on before this migration
+COMMENT ON COLUMN "standing_orders"."occurrences" IS 'occurrences that were paid; only these count towards max_occurrences';
+
+COMMENT ON COLUMN "standing_orders"."skipped_occurrences" IS 'occurrences given up on after their retries';
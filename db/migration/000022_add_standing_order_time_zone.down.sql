@@ -0,0 +1 @@
+alter table if exists standing_orders drop column if exists time_zone;
@@ -0,0 +1,3 @@
+ALTER TABLE "standing_orders" ADD COLUMN "time_zone" varchar NOT NULL DEFAULT 'UTC';
+
+COMMENT ON COLUMN "standing_orders"."time_zone" IS 'the IANA time zone the occurrences keep the day and time of day of start_at in';
@@ -0,0 +1,47 @@
+// Package migration embeds the SQL migrations, so the server can apply them without the files on disk
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var migrations embed.FS
+
+// FS returns the embedded migrations, named the way golang-migrate expects
+func FS() fs.FS {
+	return migrations
+}
+
+// New creates a migrate instance that applies the embedded migrations to the database at dbSource.
+// golang-migrate holds a Postgres advisory lock while it migrates, so concurrent callers wait for each other,
+// giving up with migrate.ErrLockTimeout after lockTimeout
+func New(dbSource string, lockTimeout time.Duration) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot load migrations: %w", err)
+	}
+
+	databaseURL, err := url.Parse(dbSource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse db source: %w", err)
+	}
+	// the pgx v5 driver registers itself under its own scheme
+	databaseURL.Scheme = "pgx5"
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	m.LockTimeout = lockTimeout
+	return m, nil
+}
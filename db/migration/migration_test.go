@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	ups, err := fs.Glob(FS(), "*.up.sql")
+	assert.NoError(t, err)
+	assert.Contains(t, ups, "000001_init_schema.up.sql")
+
+	// every migration can be rolled back
+	for _, up := range ups {
+		_, err := fs.Stat(FS(), strings.TrimSuffix(up, ".up.sql")+".down.sql")
+		assert.NoError(t, err, up)
+	}
+}
@@ -18,9 +18,17 @@ FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
 SELECT * FROM accounts
+WHERE owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(row_limit);
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -28,10 +36,23 @@ SET balance = $2
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
 -- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
+  AND (sqlc.arg(amount) >= 0 OR balance + sqlc.arg(amount) >= -overdraft_limit)
 RETURNING *;
 
 -- name: DeleteAccount :exec
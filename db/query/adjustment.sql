@@ -0,0 +1,12 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  amount,
+  reason
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetAdjustment :one
+SELECT * FROM adjustments
+WHERE id = $1 LIMIT 1;
@@ -1,9 +1,11 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  adjustment_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +17,44 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: GetEntryDetails :one
+SELECT
+  sqlc.embed(e),
+  t.from_account_id AS transfer_from_account_id,
+  t.to_account_id AS transfer_to_account_id,
+  t.amount AS transfer_amount,
+  t.to_amount AS transfer_to_amount,
+  t.exchange_rate AS transfer_exchange_rate,
+  t.created_at AS transfer_created_at,
+  fa.currency AS transfer_from_currency,
+  ta.currency AS transfer_to_currency
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts fa ON fa.id = t.from_account_id
+LEFT JOIN accounts ta ON ta.id = t.to_account_id
+WHERE e.id = $1 LIMIT 1;
+
+-- name: ListAccountEntries :many
+-- Lists an account's entries with the transfer that produced each one, paged by (created_at, id)
+SELECT
+  sqlc.embed(e),
+  t.from_account_id AS transfer_from_account_id,
+  t.to_account_id AS transfer_to_account_id,
+  t.amount AS transfer_amount,
+  t.to_amount AS transfer_to_amount,
+  t.exchange_rate AS transfer_exchange_rate,
+  t.created_at AS transfer_created_at,
+  fa.currency AS transfer_from_currency,
+  ta.currency AS transfer_to_currency
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts fa ON fa.id = t.from_account_id
+LEFT JOIN accounts ta ON ta.id = t.to_account_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(to_time))
+  AND (e.created_at, e.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY e.created_at, e.id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,29 @@
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1
+  AND key = $2
+  AND expires_at > now()
+LIMIT 1;
+
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  key,
+  username,
+  request_path,
+  request_hash,
+  response_status,
+  response_body,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (username, key) DO UPDATE
+SET request_path = EXCLUDED.request_path,
+    request_hash = EXCLUDED.request_hash,
+    response_status = EXCLUDED.response_status,
+    response_body = EXCLUDED.response_body,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING *;
+
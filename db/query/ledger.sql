@@ -0,0 +1,42 @@
+-- name: ListAccountLedgerBalances :many
+-- the accounts are batched before the join, so each batch reads a bounded set of entries
+WITH batch AS (
+  SELECT a.id, a.balance FROM accounts AS a
+  WHERE a.id > sqlc.arg(after_id)
+  ORDER BY a.id
+  LIMIT sqlc.arg(batch_size)
+)
+SELECT
+  b.id,
+  b.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM batch AS b
+LEFT JOIN entries AS e ON e.account_id = b.id
+GROUP BY b.id, b.balance
+ORDER BY b.id;
+
+-- name: ListTransferLedgerEntries :many
+-- a balanced transfer has exactly two entries, debiting the from account and crediting the to account
+WITH batch AS (
+  SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount FROM transfers AS t
+  WHERE t.id > sqlc.arg(after_id)
+  ORDER BY t.id
+  LIMIT sqlc.arg(batch_size)
+)
+SELECT
+  b.id,
+  COUNT(e.id) AS entry_count,
+  COUNT(e.id) FILTER (WHERE e.account_id = b.from_account_id AND e.amount = -b.amount) AS debit_count,
+  COUNT(e.id) FILTER (WHERE e.account_id = b.to_account_id AND e.amount = b.to_amount) AS credit_count
+FROM batch AS b
+LEFT JOIN entries AS e ON e.transfer_id = b.id
+GROUP BY b.id
+ORDER BY b.id;
+
+-- name: ListOrphanEntries :many
+SELECT * FROM entries
+WHERE transfer_id IS NULL
+  AND adjustment_id IS NULL
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,56 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  execute_at,
+  next_attempt_at
+) VALUES (
+  $1, $2, $3, $4, $4
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ClaimDueScheduledTransfer :one
+-- Claims the next pending transfer that is due by pushing its next attempt back to lease_until,
+-- so other schedulers leave it alone while it runs. Rows being claimed by another scheduler are skipped,
+-- and a transfer whose scheduler died is claimed again once the lease runs out
+UPDATE scheduled_transfers
+SET
+  attempts = attempts + 1,
+  next_attempt_at = sqlc.arg(lease_until)
+WHERE id = (
+  SELECT due.id FROM scheduled_transfers due
+  WHERE due.status = 'pending' AND due.next_attempt_at <= sqlc.arg(now)
+  ORDER BY due.next_attempt_at, due.id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RetryScheduledTransfer :one
+-- Records why an attempt failed and when to try again, leaving the transfer pending
+UPDATE scheduled_transfers
+SET
+  last_error = sqlc.arg(last_error),
+  next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: FinishScheduledTransfer :one
+-- Moves a pending transfer to its final status. It matches no row once the transfer has left pending
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  failure_reason = sqlc.narg(failure_reason),
+  processed_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
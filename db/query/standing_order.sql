@@ -0,0 +1,82 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (
+  from_account_id,
+  to_account_id,
+  amount,
+  frequency,
+  "interval",
+  start_at,
+  end_at,
+  max_occurrences,
+  time_zone,
+  next_run_at
+) VALUES (
+  sqlc.arg(from_account_id),
+  sqlc.arg(to_account_id),
+  sqlc.arg(amount),
+  sqlc.arg(frequency),
+  sqlc.arg(interval),
+  sqlc.arg(start_at),
+  sqlc.narg(end_at),
+  sqlc.narg(max_occurrences),
+  sqlc.arg(time_zone),
+  sqlc.arg(start_at)
+) RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetStandingOrderForUpdate :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListStandingOrders :many
+SELECT
+  sqlc.embed(so),
+  a.currency
+FROM standing_orders so
+JOIN accounts a ON a.id = so.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND (so.created_at, so.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY so.created_at, so.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ClaimDueStandingOrder :one
+-- Leases the next active order that is due by pushing its next run back to lease_until.
+-- Rows locked by another worker are skipped, so workers running at the same time each claim a different order,
+-- and an order whose worker stopped before finishing it falls due again once the lease runs out
+UPDATE standing_orders
+SET next_run_at = sqlc.arg(lease_until)
+WHERE id = (
+  SELECT due.id FROM standing_orders due
+  WHERE due.status = 'active' AND due.next_run_at <= sqlc.arg(now)
+  ORDER BY due.next_run_at, due.id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateStandingOrder :one
+UPDATE standing_orders
+SET
+  amount = sqlc.arg(amount),
+  end_at = sqlc.narg(end_at),
+  max_occurrences = sqlc.narg(max_occurrences),
+  occurrences = sqlc.arg(occurrences),
+  skipped_occurrences = sqlc.arg(skipped_occurrences),
+  next_run_at = sqlc.narg(next_run_at),
+  retry_count = sqlc.arg(retry_count),
+  last_error = sqlc.narg(last_error),
+  status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CancelStandingOrder :one
+UPDATE standing_orders
+SET
+  status = 'cancelled',
+  next_run_at = NULL
+WHERE id = $1 AND status = 'active'
+RETURNING *;
@@ -0,0 +1,40 @@
+-- name: GetStatementOpeningBalance :one
+SELECT (a.balance - COALESCE((
+  SELECT SUM(e.amount)
+  FROM entries e
+  WHERE e.account_id = a.id
+    AND e.created_at >= sqlc.arg(from_time)
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: ListStatementLines :many
+WITH opening AS (
+  SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount)
+    FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= sqlc.arg(from_time)
+  ), 0))::bigint AS balance
+  FROM accounts a
+  WHERE a.id = sqlc.arg(account_id)
+)
+SELECT
+  e.id AS entry_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner,
+  (opening.balance + SUM(e.amount) OVER (ORDER BY e.created_at, e.id))::bigint AS running_balance
+FROM entries e
+CROSS JOIN opening
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+  WHEN t.from_account_id = e.account_id THEN t.to_account_id
+  ELSE t.from_account_id
+END
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
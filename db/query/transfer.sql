@@ -2,20 +2,134 @@
 INSERT INTO transfers (
   amount,
   from_account_id,
-  to_account_id
+  to_account_id,
+  to_amount,
+  exchange_rate,
+  reverses_transfer_id,
+  reversal_reason,
+  standing_order_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetTransferReversedAmounts :one
+-- Sums the reversals of a transfer. Amount is debited from the original to account
+-- and to_amount credited back to the original from account
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS reversed_amount,
+  COALESCE(SUM(to_amount), 0)::bigint AS reversed_to_amount
+FROM transfers
+WHERE reverses_transfer_id = sqlc.arg(transfer_id)::bigint;
+
+-- name: GetTransferDetails :one
+SELECT
+  sqlc.embed(t),
+  fa.owner AS from_owner,
+  fa.currency AS from_currency,
+  ta.owner AS to_owner,
+  ta.currency AS to_currency,
+  (SELECT COALESCE(SUM(r.amount), 0) FROM transfers r WHERE r.reverses_transfer_id = t.id)::bigint AS reversed_amount
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE t.id = $1 LIMIT 1;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE
+    from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id)
 ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+LIMIT sqlc.arg(row_limit)
+OFFSET sqlc.arg(row_offset);
+
+-- name: ListUserTransfersNewestFirst :many
+-- Lists the transfers the owner sent or received, optionally narrowed by the filters.
+-- Direction is from the owner's side, so a transfer between two of their own accounts is both.
+-- The amount range applies to whichever leg of the transfer is in the filter currency.
+-- Without a cursor the page starts at the newest transfer
+SELECT
+  sqlc.embed(t),
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    (sqlc.narg(direction)::varchar IS DISTINCT FROM 'incoming'
+      AND fa.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)))
+    OR
+    (sqlc.narg(direction)::varchar IS DISTINCT FROM 'outgoing'
+      AND ta.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)))
+  )
+  AND (
+    sqlc.narg(currency)::varchar IS NULL
+    OR
+    (fa.currency = sqlc.narg(currency)
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)))
+    OR
+    (ta.currency = sqlc.narg(currency)
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR t.to_amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR t.to_amount <= sqlc.narg(max_amount)))
+  )
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) < (
+    COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'),
+    COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807)
+  )
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUserTransfersOldestFirst :many
+-- Same filters as ListUserTransfersNewestFirst, paged the other way.
+-- Without a cursor the page starts at the oldest transfer
+SELECT
+  sqlc.embed(t),
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    (sqlc.narg(direction)::varchar IS DISTINCT FROM 'incoming'
+      AND fa.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)))
+    OR
+    (sqlc.narg(direction)::varchar IS DISTINCT FROM 'outgoing'
+      AND ta.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)))
+  )
+  AND (
+    sqlc.narg(currency)::varchar IS NULL
+    OR
+    (fa.currency = sqlc.narg(currency)
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)))
+    OR
+    (ta.currency = sqlc.narg(currency)
+      AND (sqlc.narg(min_amount)::bigint IS NULL OR t.to_amount >= sqlc.narg(min_amount))
+      AND (sqlc.narg(max_amount)::bigint IS NULL OR t.to_amount <= sqlc.narg(max_amount)))
+  )
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) > (
+    COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'),
+    COALESCE(sqlc.narg(after_id)::bigint, 0)
+  )
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(row_limit);
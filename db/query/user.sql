@@ -10,4 +10,22 @@ INSERT INTO users (
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1;
\ No newline at end of file
+WHERE username = $1;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: DisableUser :one
+UPDATE users
+SET is_disabled = true
+WHERE username = $1
+RETURNING *;
@@ -0,0 +1,17 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO verify_emails (
+  username,
+  email,
+  secret_code
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: UpdateVerifyEmail :one
+UPDATE verify_emails
+SET is_used = true
+WHERE id = @id
+  AND secret_code = @secret_code
+  AND is_used = false
+  AND expired_at > now()
+RETURNING *;
\ No newline at end of file
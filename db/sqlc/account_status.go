@@ -0,0 +1,45 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// ErrInvalidStatusChange is returned when an account cannot move from its status to the requested one
+var ErrInvalidStatusChange = errors.New("invalid account status change")
+
+// ErrAccountNotEmpty is returned when closing an account whose balance cannot be swept to another account
+var ErrAccountNotEmpty = errors.New("account balance must be zero or swept to another account")
+
+// AccountStatusError is returned when a transfer involves an account that is not active
+type AccountStatusError struct {
+	AccountID int64
+	Status    string
+}
+
+func (err *AccountStatusError) Error() string {
+	return fmt.Sprintf("account [%d] is %s", err.AccountID, err.Status)
+}
+
+// accountStatusChanges lists the statuses each status can move to.
+// Closed is final, and a frozen account must be unfrozen before it can be closed
+var accountStatusChanges = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive},
+}
+
+// CanChangeAccountStatus reports whether an account can move from one status to the other
+func CanChangeAccountStatus(from string, to string) bool {
+	for _, status := range accountStatusChanges[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
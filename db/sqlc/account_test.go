@@ -10,10 +10,14 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithBalance(t, util.RandomMoney())
+}
+
+func createRandomAccountWithBalance(t *testing.T, balance int64) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
-		Balance:  util.RandomMoney(),
+		Balance:  balance,
 		Currency: util.RandomCurrency(),
 		Owner:    user.Username,
 	}
@@ -36,6 +40,18 @@ func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
 
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testStore.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 500,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), account2.OverdraftLimit)
+	assert.Equal(t, account1.Balance, account2.Balance)
+}
+
 func TestDeleteAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 	err := testStore.DeleteAccount(context.Background(), account1.ID)
@@ -61,26 +77,64 @@ func TestGetAccount(t *testing.T) {
 }
 
 func TestListAccount(t *testing.T) {
+	var lastAccount Account
 	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
+		lastAccount = createRandomAccount(t)
 	}
 
 	arg := ListAccountsParams{
+		Owner:  lastAccount.Owner,
 		Limit:  5,
 		Offset: 0,
 	}
 
 	accounts, err := testStore.ListAccounts(context.Background(), arg)
 	assert.NoError(t, err)
-	assert.Equal(t, int(arg.Limit), len(accounts))
 	assert.NotEmpty(t, accounts)
 
 	for _, account := range accounts {
 		assert.NotEmpty(t, account)
+		assert.Equal(t, lastAccount.Owner, account.Owner)
 		assert.NotEmpty(t, account.CreatedAt)
 	}
 }
 
+func TestListAccountsAfter(t *testing.T) {
+	user := createRandomUser(t)
+
+	var created []int64
+	for _, currency := range []string{"USD", "EUR", "CAD", "GBP"} {
+		account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: currency,
+		})
+		assert.NoError(t, err)
+		created = append(created, account.ID)
+	}
+
+	var listed []int64
+	arg := ListAccountsAfterParams{
+		Owner:    user.Username,
+		RowLimit: 3,
+	}
+	for {
+		accounts, err := testStore.ListAccountsAfter(context.Background(), arg)
+		assert.NoError(t, err)
+		for _, account := range accounts {
+			listed = append(listed, account.ID)
+		}
+		if len(accounts) < int(arg.RowLimit) {
+			break
+		}
+
+		last := accounts[len(accounts)-1]
+		arg.AfterCreatedAt = last.CreatedAt.Time
+		arg.AfterID = last.ID
+	}
+
+	assert.Equal(t, created, listed)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 
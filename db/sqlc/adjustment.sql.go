@@ -0,0 +1,57 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: adjustment.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAdjustment = `-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  amount,
+  reason
+) VALUES (
+  $1, $2, $3
+) RETURNING id, account_id, amount, reason, created_at
+`
+
+type CreateAdjustmentParams struct {
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+}
+
+func (q *Queries) CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error) {
+	row := q.db.QueryRow(ctx, createAdjustment, arg.AccountID, arg.Amount, arg.Reason)
+	var i Adjustment
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAdjustment = `-- name: GetAdjustment :one
+SELECT id, account_id, amount, reason, created_at FROM adjustments
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAdjustment(ctx context.Context, id int64) (Adjustment, error) {
+	row := q.db.QueryRow(ctx, getAdjustment, id)
+	var i Adjustment
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testStore.ListCurrencies(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, currencies)
+
+	enabled := make(map[string]bool)
+	for _, currency := range currencies {
+		enabled[currency.Code] = currency.Enabled
+	}
+
+	for _, code := range []string{util.CAD, util.EUR, util.GBP, util.USD} {
+		assert.True(t, enabled[code], code)
+	}
+}
+
+func TestUpdateCurrencyEnabled(t *testing.T) {
+	currency1, err := testStore.GetCurrency(context.Background(), "CHF")
+	assert.NoError(t, err)
+
+	currency2, err := testStore.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    currency1.Code,
+		Enabled: !currency1.Enabled,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, !currency1.Enabled, currency2.Enabled)
+	assert.Equal(t, currency1.Exponent, currency2.Exponent)
+	assert.Equal(t, currency1.DisplayName, currency2.DisplayName)
+	assert.True(t, currency2.UpdatedAt.After(currency1.UpdatedAt))
+
+	// restore the seeded state for other tests
+	_, err = testStore.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    currency1.Code,
+		Enabled: currency1.Enabled,
+	})
+	assert.NoError(t, err)
+}
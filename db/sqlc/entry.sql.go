@@ -7,36 +7,51 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  adjustment_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount, created_at, transfer_id, adjustment_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID    int64       `json:"account_id"`
+	Amount       int64       `json:"amount"`
+	TransferID   pgtype.Int8 `json:"transfer_id"`
+	AdjustmentID pgtype.Int8 `json:"adjustment_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.AdjustmentID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +63,153 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
+	)
+	return i, err
+}
+
+const getEntryDetails = `-- name: GetEntryDetails :one
+SELECT
+  e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.adjustment_id,
+  t.from_account_id AS transfer_from_account_id,
+  t.to_account_id AS transfer_to_account_id,
+  t.amount AS transfer_amount,
+  t.to_amount AS transfer_to_amount,
+  t.exchange_rate AS transfer_exchange_rate,
+  t.created_at AS transfer_created_at,
+  fa.currency AS transfer_from_currency,
+  ta.currency AS transfer_to_currency
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts fa ON fa.id = t.from_account_id
+LEFT JOIN accounts ta ON ta.id = t.to_account_id
+WHERE e.id = $1 LIMIT 1
+`
+
+type GetEntryDetailsRow struct {
+	Entry                 Entry              `json:"entry"`
+	TransferFromAccountID pgtype.Int8        `json:"transfer_from_account_id"`
+	TransferToAccountID   pgtype.Int8        `json:"transfer_to_account_id"`
+	TransferAmount        pgtype.Int8        `json:"transfer_amount"`
+	TransferToAmount      pgtype.Int8        `json:"transfer_to_amount"`
+	TransferExchangeRate  *money.Rate        `json:"transfer_exchange_rate"`
+	TransferCreatedAt     pgtype.Timestamptz `json:"transfer_created_at"`
+	TransferFromCurrency  pgtype.Text        `json:"transfer_from_currency"`
+	TransferToCurrency    pgtype.Text        `json:"transfer_to_currency"`
+}
+
+func (q *Queries) GetEntryDetails(ctx context.Context, id int64) (GetEntryDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getEntryDetails, id)
+	var i GetEntryDetailsRow
+	err := row.Scan(
+		&i.Entry.ID,
+		&i.Entry.AccountID,
+		&i.Entry.Amount,
+		&i.Entry.CreatedAt,
+		&i.Entry.TransferID,
+		&i.Entry.AdjustmentID,
+		&i.TransferFromAccountID,
+		&i.TransferToAccountID,
+		&i.TransferAmount,
+		&i.TransferToAmount,
+		&i.TransferExchangeRate,
+		&i.TransferCreatedAt,
+		&i.TransferFromCurrency,
+		&i.TransferToCurrency,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT
+  e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.adjustment_id,
+  t.from_account_id AS transfer_from_account_id,
+  t.to_account_id AS transfer_to_account_id,
+  t.amount AS transfer_amount,
+  t.to_amount AS transfer_to_amount,
+  t.exchange_rate AS transfer_exchange_rate,
+  t.created_at AS transfer_created_at,
+  fa.currency AS transfer_from_currency,
+  ta.currency AS transfer_to_currency
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts fa ON fa.id = t.from_account_id
+LEFT JOIN accounts ta ON ta.id = t.to_account_id
+WHERE e.account_id = $1
+  AND ($2::timestamptz IS NULL OR e.created_at >= $2)
+  AND ($3::timestamptz IS NULL OR e.created_at < $3)
+  AND (e.created_at, e.id) > ($4::timestamptz, $5::bigint)
+ORDER BY e.created_at, e.id
+LIMIT $6
+`
+
+type ListAccountEntriesParams struct {
+	AccountID      int64              `json:"account_id"`
+	FromTime       pgtype.Timestamptz `json:"from_time"`
+	ToTime         pgtype.Timestamptz `json:"to_time"`
+	AfterCreatedAt time.Time          `json:"after_created_at"`
+	AfterID        int64              `json:"after_id"`
+	RowLimit       int32              `json:"row_limit"`
+}
+
+type ListAccountEntriesRow struct {
+	Entry                 Entry              `json:"entry"`
+	TransferFromAccountID pgtype.Int8        `json:"transfer_from_account_id"`
+	TransferToAccountID   pgtype.Int8        `json:"transfer_to_account_id"`
+	TransferAmount        pgtype.Int8        `json:"transfer_amount"`
+	TransferToAmount      pgtype.Int8        `json:"transfer_to_amount"`
+	TransferExchangeRate  *money.Rate        `json:"transfer_exchange_rate"`
+	TransferCreatedAt     pgtype.Timestamptz `json:"transfer_created_at"`
+	TransferFromCurrency  pgtype.Text        `json:"transfer_from_currency"`
+	TransferToCurrency    pgtype.Text        `json:"transfer_to_currency"`
+}
+
+// Lists an account's entries with the transfer that produced each one, paged by (created_at, id)
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.Entry.ID,
+			&i.Entry.AccountID,
+			&i.Entry.Amount,
+			&i.Entry.CreatedAt,
+			&i.Entry.TransferID,
+			&i.Entry.AdjustmentID,
+			&i.TransferFromAccountID,
+			&i.TransferToAccountID,
+			&i.TransferAmount,
+			&i.TransferToAmount,
+			&i.TransferExchangeRate,
+			&i.TransferCreatedAt,
+			&i.TransferFromCurrency,
+			&i.TransferToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -80,6 +236,8 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
 		); err != nil {
 			return nil, err
 		}
@@ -68,3 +68,71 @@ func TestListEntries(t *testing.T) {
 		assert.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestGetEntryDetails(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccount(t)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+
+	details, err := testStore.GetEntryDetails(context.Background(), result.FromEntry.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result.FromEntry.ID, details.Entry.ID)
+	assert.Equal(t, result.Transfer.ID, details.Entry.TransferID.Int64)
+	assert.Equal(t, account1.ID, details.TransferFromAccountID.Int64)
+	assert.Equal(t, account2.ID, details.TransferToAccountID.Int64)
+	assert.Equal(t, result.Transfer.Amount, details.TransferAmount.Int64)
+	assert.Equal(t, account1.Currency, details.TransferFromCurrency.String)
+	assert.Equal(t, account2.Currency, details.TransferToCurrency.String)
+
+	entry := createRandomEntry(t, account2)
+	details, err = testStore.GetEntryDetails(context.Background(), entry.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entry, details.Entry)
+	assert.False(t, details.TransferFromAccountID.Valid)
+	assert.False(t, details.TransferCreatedAt.Valid)
+}
+
+func TestListAccountEntries(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var created []int64
+	for i := 0; i < 10; i++ {
+		created = append(created, createRandomEntry(t, account).ID)
+	}
+
+	var listed []int64
+	arg := ListAccountEntriesParams{
+		AccountID: account.ID,
+		RowLimit:  3,
+	}
+	for {
+		entries, err := testStore.ListAccountEntries(context.Background(), arg)
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			listed = append(listed, entry.Entry.ID)
+		}
+		if len(entries) < int(arg.RowLimit) {
+			break
+		}
+
+		last := entries[len(entries)-1]
+		arg.AfterCreatedAt = last.Entry.CreatedAt.Time
+		arg.AfterID = last.Entry.ID
+	}
+	assert.Equal(t, created, listed)
+
+	// nothing was created before the account existed
+	entries, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		ToTime:    account.CreatedAt,
+		RowLimit:  10,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -8,11 +8,15 @@ import (
 )
 
 const (
-	UniqueViolation = "23505"
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
 
+// ErrInsufficientFunds is returned when a debit would take the balance below the overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 var ErrUniqueVioloation = &pgconn.PgError{
 	Code: UniqueViolation,
 }
@@ -3,11 +3,18 @@ package db
 import (
 	"context"
 	"fmt"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // ExecTx executes a function within a database transaction
 func (store *SLQStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.connPool.Begin(ctx)
+	return store.execTxWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// execTxWithOptions executes a function within a database transaction with the given isolation and access mode
+func (store *SLQStore) execTxWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.connPool.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
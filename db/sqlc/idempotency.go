@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIdempotencyKeyInUse is returned when another request has already stored a live idempotency key
+var ErrIdempotencyKeyInUse = errors.New("idempotency key is already in use")
+
+// IdempotencyKeyParams describes the idempotency key saved together with the result of a transaction
+type IdempotencyKeyParams struct {
+	Key            string
+	Username       string
+	RequestPath    string
+	RequestHash    string
+	ResponseStatus int32
+	ExpiresAt      time.Time
+	// NewResponse builds the stored response body from the transaction result.
+	// The result itself is stored when it is nil
+	NewResponse func(result interface{}) interface{}
+}
+
+// saveIdempotencyKey stores the key with the JSON encoded response inside the running transaction.
+// A nil key is a no-op, so callers without an Idempotency-Key header are not affected
+func saveIdempotencyKey(ctx context.Context, q *Queries, arg *IdempotencyKeyParams, response interface{}) error {
+	if arg == nil {
+		return nil
+	}
+
+	if arg.NewResponse != nil {
+		response = arg.NewResponse(response)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("cannot marshal idempotent response: %w", err)
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Key:            arg.Key,
+		Username:       arg.Username,
+		RequestPath:    arg.RequestPath,
+		RequestHash:    arg.RequestHash,
+		ResponseStatus: arg.ResponseStatus,
+		ResponseBody:   body,
+		ExpiresAt:      arg.ExpiresAt,
+	})
+	if errors.Is(err, ErrRecordNotFound) {
+		// the upsert only returns a row when the existing key has expired
+		return ErrIdempotencyKeyInUse
+	}
+
+	return err
+}
@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: idempotency_key.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  key,
+  username,
+  request_path,
+  request_hash,
+  response_status,
+  response_body,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (username, key) DO UPDATE
+SET request_path = EXCLUDED.request_path,
+    request_hash = EXCLUDED.request_hash,
+    response_status = EXCLUDED.response_status,
+    response_body = EXCLUDED.response_body,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING key, username, request_path, request_hash, response_status, response_body, created_at, expires_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	Key            string    `json:"key"`
+	Username       string    `json:"username"`
+	RequestPath    string    `json:"request_path"`
+	RequestHash    string    `json:"request_hash"`
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, createIdempotencyKey,
+		arg.Key,
+		arg.Username,
+		arg.RequestPath,
+		arg.RequestHash,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+		arg.ExpiresAt,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Username,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, username, request_path, request_hash, response_status, response_body, created_at, expires_at FROM idempotency_keys
+WHERE username = $1
+  AND key = $2
+  AND expires_at > now()
+LIMIT 1
+`
+
+type GetIdempotencyKeyParams struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Username, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Username,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
@@ -0,0 +1,156 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: ledger.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listAccountLedgerBalances = `-- name: ListAccountLedgerBalances :many
+WITH batch AS (
+  SELECT a.id, a.balance FROM accounts AS a
+  WHERE a.id > $1
+  ORDER BY a.id
+  LIMIT $2
+)
+SELECT
+  b.id,
+  b.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM batch AS b
+LEFT JOIN entries AS e ON e.account_id = b.id
+GROUP BY b.id, b.balance
+ORDER BY b.id
+`
+
+type ListAccountLedgerBalancesParams struct {
+	AfterID   int64 `json:"after_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ListAccountLedgerBalancesRow struct {
+	ID           int64 `json:"id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+}
+
+// the accounts are batched before the join, so each batch reads a bounded set of entries
+func (q *Queries) ListAccountLedgerBalances(ctx context.Context, arg ListAccountLedgerBalancesParams) ([]ListAccountLedgerBalancesRow, error) {
+	rows, err := q.db.Query(ctx, listAccountLedgerBalances, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountLedgerBalancesRow{}
+	for rows.Next() {
+		var i ListAccountLedgerBalancesRow
+		if err := rows.Scan(&i.ID, &i.Balance, &i.EntriesTotal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanEntries = `-- name: ListOrphanEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
+WHERE transfer_id IS NULL
+  AND adjustment_id IS NULL
+  AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListOrphanEntriesParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ListOrphanEntries(ctx context.Context, arg ListOrphanEntriesParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listOrphanEntries, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferLedgerEntries = `-- name: ListTransferLedgerEntries :many
+WITH batch AS (
+  SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount FROM transfers AS t
+  WHERE t.id > $1
+  ORDER BY t.id
+  LIMIT $2
+)
+SELECT
+  b.id,
+  COUNT(e.id) AS entry_count,
+  COUNT(e.id) FILTER (WHERE e.account_id = b.from_account_id AND e.amount = -b.amount) AS debit_count,
+  COUNT(e.id) FILTER (WHERE e.account_id = b.to_account_id AND e.amount = b.to_amount) AS credit_count
+FROM batch AS b
+LEFT JOIN entries AS e ON e.transfer_id = b.id
+GROUP BY b.id
+ORDER BY b.id
+`
+
+type ListTransferLedgerEntriesParams struct {
+	AfterID   int64 `json:"after_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ListTransferLedgerEntriesRow struct {
+	ID          int64 `json:"id"`
+	EntryCount  int64 `json:"entry_count"`
+	DebitCount  int64 `json:"debit_count"`
+	CreditCount int64 `json:"credit_count"`
+}
+
+// a balanced transfer has exactly two entries, debiting the from account and crediting the to account
+func (q *Queries) ListTransferLedgerEntries(ctx context.Context, arg ListTransferLedgerEntriesParams) ([]ListTransferLedgerEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listTransferLedgerEntries, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransferLedgerEntriesRow{}
+	for rows.Next() {
+		var i ListTransferLedgerEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntryCount,
+			&i.DebitCount,
+			&i.CreditCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	TransferMissingEntries    = "missing_entries"
+	TransferUnbalancedEntries = "unbalanced_entries"
+)
+
+// CheckLedgerParams contains the input parameters of the ledger check
+type CheckLedgerParams struct {
+	// BatchSize is how many accounts, transfers or entries each query reads
+	BatchSize int32
+}
+
+// LedgerReport lists every place where the ledger doesn't balance
+type LedgerReport struct {
+	AccountsChecked    int64              `json:"accounts_checked"`
+	TransfersChecked   int64              `json:"transfers_checked"`
+	BalanceMismatches  []BalanceMismatch  `json:"balance_mismatches"`
+	TransferMismatches []TransferMismatch `json:"transfer_mismatches"`
+	OrphanEntries      []Entry            `json:"orphan_entries"`
+}
+
+// BalanceMismatch is an account whose balance is not the sum of its entries
+type BalanceMismatch struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+}
+
+// TransferMismatch is a transfer without exactly one debit and one matching credit entry
+type TransferMismatch struct {
+	TransferID int64  `json:"transfer_id"`
+	EntryCount int64  `json:"entry_count"`
+	Problem    string `json:"problem"`
+}
+
+// OK reports whether the ledger balances
+func (report LedgerReport) OK() bool {
+	return len(report.BalanceMismatches) == 0 && len(report.TransferMismatches) == 0 && len(report.OrphanEntries) == 0
+}
+
+// CheckLedger scans the accounts, transfers and entries in batches of arg.BatchSize and reports
+// accounts whose balance differs from the sum of their entries, transfers whose entries are
+// missing or don't balance, and entries that don't belong to any transfer or adjustment.
+// Each batch is read in its own statement, so it is safe to run against a live database
+func (q *Queries) CheckLedger(ctx context.Context, arg CheckLedgerParams) (LedgerReport, error) {
+	if arg.BatchSize < 1 {
+		return LedgerReport{}, errors.New("batch size must be at least 1")
+	}
+
+	report := LedgerReport{
+		BalanceMismatches:  []BalanceMismatch{},
+		TransferMismatches: []TransferMismatch{},
+		OrphanEntries:      []Entry{},
+	}
+
+	for afterID := int64(0); ; {
+		accounts, err := q.ListAccountLedgerBalances(ctx, ListAccountLedgerBalancesParams{
+			AfterID:   afterID,
+			BatchSize: arg.BatchSize,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, account := range accounts {
+			if account.Balance != account.EntriesTotal {
+				report.BalanceMismatches = append(report.BalanceMismatches, BalanceMismatch{
+					AccountID:    account.ID,
+					Balance:      account.Balance,
+					EntriesTotal: account.EntriesTotal,
+				})
+			}
+		}
+
+		report.AccountsChecked += int64(len(accounts))
+		if len(accounts) < int(arg.BatchSize) {
+			break
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+
+	for afterID := int64(0); ; {
+		transfers, err := q.ListTransferLedgerEntries(ctx, ListTransferLedgerEntriesParams{
+			AfterID:   afterID,
+			BatchSize: arg.BatchSize,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, transfer := range transfers {
+			if transfer.EntryCount == 2 && transfer.DebitCount == 1 && transfer.CreditCount == 1 {
+				continue
+			}
+
+			problem := TransferUnbalancedEntries
+			if transfer.EntryCount < 2 {
+				problem = TransferMissingEntries
+			}
+			report.TransferMismatches = append(report.TransferMismatches, TransferMismatch{
+				TransferID: transfer.ID,
+				EntryCount: transfer.EntryCount,
+				Problem:    problem,
+			})
+		}
+
+		report.TransfersChecked += int64(len(transfers))
+		if len(transfers) < int(arg.BatchSize) {
+			break
+		}
+		afterID = transfers[len(transfers)-1].ID
+	}
+
+	for afterID := int64(0); ; {
+		entries, err := q.ListOrphanEntries(ctx, ListOrphanEntriesParams{
+			AfterID:  afterID,
+			RowLimit: arg.BatchSize,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		report.OrphanEntries = append(report.OrphanEntries, entries...)
+		if len(entries) < int(arg.BatchSize) {
+			break
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+
+	return report, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLedger(t *testing.T) {
+	// accounts that start empty only ever change through transfers, so they balance
+	account1 := createRandomAccountWithBalance(t, 0)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	_, err := testStore.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	assert.NoError(t, err)
+
+	balanced, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+
+	// a transfer without entries, an entry without a transfer and a balance without entries
+	missing := createRandomTransfer(t, account1, account2)
+	orphan := createRandomEntry(t, account2)
+	funded := createRandomAccountWithBalance(t, 100)
+
+	// a small batch size makes the check page through every table
+	report, err := testStore.CheckLedger(context.Background(), CheckLedgerParams{BatchSize: 7})
+	assert.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.NotZero(t, report.AccountsChecked)
+	assert.NotZero(t, report.TransfersChecked)
+
+	mismatches := make(map[int64]BalanceMismatch)
+	for _, mismatch := range report.BalanceMismatches {
+		mismatches[mismatch.AccountID] = mismatch
+	}
+	assert.NotContains(t, mismatches, account1.ID)
+	assert.Equal(t, BalanceMismatch{AccountID: funded.ID, Balance: 100, EntriesTotal: 0}, mismatches[funded.ID])
+	// the orphan entry is in the sum but never reached the balance
+	assert.Equal(t, orphan.Amount, mismatches[account2.ID].EntriesTotal-mismatches[account2.ID].Balance)
+
+	transfers := make(map[int64]TransferMismatch)
+	for _, mismatch := range report.TransferMismatches {
+		transfers[mismatch.TransferID] = mismatch
+	}
+	assert.NotContains(t, transfers, balanced.Transfer.ID)
+	assert.Equal(t, TransferMismatch{TransferID: missing.ID, EntryCount: 0, Problem: TransferMissingEntries}, transfers[missing.ID])
+
+	assert.Contains(t, report.OrphanEntries, orphan)
+}
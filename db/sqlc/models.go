@@ -5,6 +5,10 @@
 package db
 
 import (
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -14,6 +18,27 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// frozen and closed accounts cannot send or receive transfers
+	Status string `json:"status"`
+}
+
+// balance corrections made by an operator rather than a transfer
+type Adjustment struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	Code        string    `json:"code"`
+	Exponent    int32     `json:"exponent"`
+	Enabled     bool      `json:"enabled"`
+	DisplayName string    `json:"display_name"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type Entry struct {
@@ -22,21 +47,116 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// the transfer that created the entry, if any
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	// the adjustment that created the entry, if any
+	AdjustmentID pgtype.Int8 `json:"adjustment_id"`
+}
+
+type IdempotencyKey struct {
+	Key            string    `json:"key"`
+	Username       string    `json:"username"`
+	RequestPath    string    `json:"request_path"`
+	RequestHash    string    `json:"request_hash"`
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// in the currency of the from account; cross-currency transfers are converted when they execute
+	Amount    int64     `json:"amount"`
+	ExecuteAt time.Time `json:"execute_at"`
+	Status    string    `json:"status"`
+	// the transfer made when it was executed
+	TransferID    pgtype.Int8        `json:"transfer_id"`
+	FailureReason pgtype.Text        `json:"failure_reason"`
+	ProcessedAt   pgtype.Timestamptz `json:"processed_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	// how many times a scheduler has claimed the transfer
+	Attempts  int32       `json:"attempts"`
+	LastError pgtype.Text `json:"last_error"`
+	// execute_at at first, then pushed back while a claimed attempt runs and after an attempt fails unexpectedly
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+type StandingOrder struct {
+	ID             int64              `json:"id"`
+	FromAccountID  int64              `json:"from_account_id"`
+	ToAccountID    int64              `json:"to_account_id"`
+	Amount         int64              `json:"amount"`
+	Frequency      string             `json:"frequency"`
+	Interval       int32              `json:"interval"`
+	StartAt        time.Time          `json:"start_at"`
+	EndAt          pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences pgtype.Int4        `json:"max_occurrences"`
+	// occurrences that were paid; only these count towards max_occurrences
+	Occurrences int32 `json:"occurrences"`
+	// the next occurrence, or the next retry of a failed one; null once the order has ended
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	// failed attempts at the current occurrence
+	RetryCount int32       `json:"retry_count"`
+	LastError  pgtype.Text `json:"last_error"`
+	Status     string      `json:"status"`
+	CreatedAt  time.Time   `json:"created_at"`
+	// occurrences given up on after their retries
+	SkippedOccurrences int32 `json:"skipped_occurrences"`
+	// the IANA time zone the occurrences keep the day and time of day of start_at in
+	TimeZone string `json:"time_zone"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// must be positive, in the currency of the from account
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// must be positive, in the currency of the to account
+	ToAmount int64 `json:"to_amount"`
+	// units of the to currency for one unit of the from currency
+	ExchangeRate money.Rate `json:"exchange_rate"`
+	// the transfer this one pays back, in full or in part
+	ReversesTransferID pgtype.Int8 `json:"reverses_transfer_id"`
+	ReversalReason     pgtype.Text `json:"reversal_reason"`
+	// the standing order the transfer paid an occurrence of, if any
+	StandingOrderID pgtype.Int8 `json:"standing_order_id"`
 }
 
 type User struct {
-	Username       string             `json:"username"`
-	HashedPassword string             `json:"hashed_password"`
-	FullName       string             `json:"full_name"`
-	Email          string             `json:"email"`
-	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	Username        string             `json:"username"`
+	HashedPassword  string             `json:"hashed_password"`
+	FullName        string             `json:"full_name"`
+	Email           string             `json:"email"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	IsEmailVerified bool               `json:"is_email_verified"`
+	Role            string             `json:"role"`
+	// disabled users cannot log in
+	IsDisabled bool `json:"is_disabled"`
+}
+
+type VerifyEmail struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	SecretCode string    `json:"secret_code"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
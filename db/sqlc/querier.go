@@ -6,24 +6,91 @@ package db
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CancelStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	// Claims the next pending transfer that is due by pushing its next attempt back to lease_until,
+	// so other schedulers leave it alone while it runs. Rows being claimed by another scheduler are skipped,
+	// and a transfer whose scheduler died is claimed again once the lease runs out
+	ClaimDueScheduledTransfer(ctx context.Context, arg ClaimDueScheduledTransferParams) (ScheduledTransfer, error)
+	// Leases the next active order that is due by pushing its next run back to lease_until.
+	// Rows locked by another worker are skipped, so workers running at the same time each claim a different order,
+	// and an order whose worker stopped before finishing it falls due again once the lease runs out
+	ClaimDueStandingOrder(ctx context.Context, arg ClaimDueStandingOrderParams) (StandingOrder, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DisableUser(ctx context.Context, username string) (User, error)
+	// Moves a pending transfer to its final status. It matches no row once the transfer has left pending
+	FinishScheduledTransfer(ctx context.Context, arg FinishScheduledTransferParams) (ScheduledTransfer, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAdjustment(ctx context.Context, id int64) (Adjustment, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEntryDetails(ctx context.Context, id int64) (GetEntryDetailsRow, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	GetStandingOrderForUpdate(ctx context.Context, id int64) (StandingOrder, error)
+	GetStatementOpeningBalance(ctx context.Context, arg GetStatementOpeningBalanceParams) (int64, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferDetails(ctx context.Context, id int64) (GetTransferDetailsRow, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	// Sums the reversals of a transfer. Amount is debited from the original to account
+	// and to_amount credited back to the original from account
+	GetTransferReversedAmounts(ctx context.Context, transferID int64) (GetTransferReversedAmountsRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	// Lists an account's entries with the transfer that produced each one, paged by (created_at, id)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	// the accounts are batched before the join, so each batch reads a bounded set of entries
+	ListAccountLedgerBalances(ctx context.Context, arg ListAccountLedgerBalancesParams) ([]ListAccountLedgerBalancesRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListOrphanEntries(ctx context.Context, arg ListOrphanEntriesParams) ([]Entry, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]ListStandingOrdersRow, error)
+	ListStatementLines(ctx context.Context, arg ListStatementLinesParams) ([]ListStatementLinesRow, error)
+	// a balanced transfer has exactly two entries, debiting the from account and crediting the to account
+	ListTransferLedgerEntries(ctx context.Context, arg ListTransferLedgerEntriesParams) ([]ListTransferLedgerEntriesRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// Lists the transfers the owner sent or received, optionally narrowed by the filters.
+	// Direction is from the owner's side, so a transfer between two of their own accounts is both.
+	// The amount range applies to whichever leg of the transfer is in the filter currency.
+	// Without a cursor the page starts at the newest transfer
+	ListUserTransfersNewestFirst(ctx context.Context, arg ListUserTransfersNewestFirstParams) ([]ListUserTransfersNewestFirstRow, error)
+	// Same filters as ListUserTransfersNewestFirst, paged the other way.
+	// Without a cursor the page starts at the oldest transfer
+	ListUserTransfersOldestFirst(ctx context.Context, arg ListUserTransfersOldestFirstParams) ([]ListUserTransfersOldestFirstRow, error)
+	// Records why an attempt failed and when to try again, leaving the transfer pending
+	RetryScheduledTransfer(ctx context.Context, arg RetryScheduledTransferParams) (ScheduledTransfer, error)
+	TouchSession(ctx context.Context, id uuid.UUID) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateCurrencyEnabled(ctx context.Context, arg UpdateCurrencyEnabledParams) (Currency, error)
+	UpdateStandingOrder(ctx context.Context, arg UpdateStandingOrderParams) (StandingOrder, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	VerifyUserEmail(ctx context.Context, username string) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
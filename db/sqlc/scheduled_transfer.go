@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/drmanalo/simplebank/money"
+)
+
+const (
+	ScheduledTransferPending   = "pending"
+	ScheduledTransferExecuted  = "executed"
+	ScheduledTransferFailed    = "failed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+// ErrCannotConvert is wrapped by a conversion that can never succeed, such as a missing exchange rate.
+// A scheduled transfer or standing order that cannot be converted fails instead of being retried
+var ErrCannotConvert = errors.New("cannot convert amount")
+
+// ConvertFunc converts an amount in the from currency for a cross-currency transfer
+type ConvertFunc func(ctx context.Context, amount int64, from string, to string) (toAmount int64, exchangeRate money.Rate, err error)
+
+// isTransferFailure reports whether err is a reason for a transfer the bank makes on its own to fail,
+// such as a scheduled transfer. Other errors, such as a lost connection, are retried as if nothing happened
+func isTransferFailure(err error) bool {
+	var accountStatusErr *AccountStatusError
+	return errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrCannotConvert) ||
+		errors.As(err, &accountStatusErr)
+}
@@ -0,0 +1,237 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: scheduled_transfer.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimDueScheduledTransfer = `-- name: ClaimDueScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  attempts = attempts + 1,
+  next_attempt_at = $1
+WHERE id = (
+  SELECT due.id FROM scheduled_transfers due
+  WHERE due.status = 'pending' AND due.next_attempt_at <= $2
+  ORDER BY due.next_attempt_at, due.id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at
+`
+
+type ClaimDueScheduledTransferParams struct {
+	LeaseUntil time.Time `json:"lease_until"`
+	Now        time.Time `json:"now"`
+}
+
+// Claims the next pending transfer that is due by pushing its next attempt back to lease_until,
+// so other schedulers leave it alone while it runs. Rows being claimed by another scheduler are skipped,
+// and a transfer whose scheduler died is claimed again once the lease runs out
+func (q *Queries) ClaimDueScheduledTransfer(ctx context.Context, arg ClaimDueScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, claimDueScheduledTransfer, arg.LeaseUntil, arg.Now)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const createScheduledTransfer = `-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  execute_at,
+  next_attempt_at
+) VALUES (
+  $1, $2, $3, $4, $4
+) RETURNING id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at
+`
+
+type CreateScheduledTransferParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExecuteAt     time.Time `json:"execute_at"`
+}
+
+func (q *Queries) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, createScheduledTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ExecuteAt,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const finishScheduledTransfer = `-- name: FinishScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  status = $1,
+  transfer_id = $2,
+  failure_reason = $3,
+  processed_at = now()
+WHERE id = $4 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at
+`
+
+type FinishScheduledTransferParams struct {
+	Status        string      `json:"status"`
+	TransferID    pgtype.Int8 `json:"transfer_id"`
+	FailureReason pgtype.Text `json:"failure_reason"`
+	ID            int64       `json:"id"`
+}
+
+// Moves a pending transfer to its final status. It matches no row once the transfer has left pending
+func (q *Queries) FinishScheduledTransfer(ctx context.Context, arg FinishScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, finishScheduledTransfer,
+		arg.Status,
+		arg.TransferID,
+		arg.FailureReason,
+		arg.ID,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const getScheduledTransfer = `-- name: GetScheduledTransfer :one
+SELECT id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, getScheduledTransfer, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const getScheduledTransferForUpdate = `-- name: GetScheduledTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, getScheduledTransferForUpdate, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const retryScheduledTransfer = `-- name: RetryScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  last_error = $1,
+  next_attempt_at = $2
+WHERE id = $3 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, execute_at, status, transfer_id, failure_reason, processed_at, created_at, attempts, last_error, next_attempt_at
+`
+
+type RetryScheduledTransferParams struct {
+	LastError     pgtype.Text `json:"last_error"`
+	NextAttemptAt time.Time   `json:"next_attempt_at"`
+	ID            int64       `json:"id"`
+}
+
+// Records why an attempt failed and when to try again, leaving the transfer pending
+func (q *Queries) RetryScheduledTransfer(ctx context.Context, arg RetryScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.db.QueryRow(ctx, retryScheduledTransfer, arg.LastError, arg.NextAttemptAt, arg.ID)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ExecuteAt,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doubleConvert converts at a rate of 2, whatever the currencies
+func doubleConvert(ctx context.Context, amount int64, from string, to string) (int64, money.Rate, error) {
+	return amount * 2, money.MustParseRate("2"), nil
+}
+
+// randomDueTime is far enough in the past that rows of other tests are not due yet at that time
+func randomDueTime() time.Time {
+	return time.Now().AddDate(-30, 0, 0).Add(-time.Duration(util.RandomInt(0, 1e9)) * time.Microsecond).Truncate(time.Microsecond)
+}
+
+// cancelDueScheduledTransfers cancels the pending transfers that other tests and earlier runs left
+// due within an hour of now, so the scheduler claims only the ones the calling test schedules
+func cancelDueScheduledTransfers(t *testing.T, now time.Time) {
+	_, err := testStore.(*SLQStore).connPool.Exec(context.Background(),
+		"UPDATE scheduled_transfers SET status = 'cancelled' WHERE status = 'pending' AND next_attempt_at <= $1",
+		now.Add(time.Hour))
+	assert.NoError(t, err)
+}
+
+// createAccountInCurrency creates an account holding the given currency
+func createAccountInCurrency(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	assert.NoError(t, err)
+	return account
+}
+
+func scheduleTransfer(t *testing.T, from Account, to Account, amount int64, executeAt time.Time) ScheduledTransfer {
+	scheduled, err := testStore.CreateScheduledTransferTx(context.Background(), CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: CreateScheduledTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+			ExecuteAt:     executeAt,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ScheduledTransferPending, scheduled.Status)
+	return scheduled
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+
+	now := randomDueTime()
+	cancelDueScheduledTransfers(t, now)
+	scheduled := scheduleTransfer(t, account1, account2, 100, now)
+	later := scheduleTransfer(t, account1, account2, 100, now.Add(time.Second))
+
+	arg := ExecuteScheduledTransferTxParams{
+		Now:     now,
+		Convert: doubleConvert,
+		Retry:   RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+	}
+	result, err := testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+
+	assert.Equal(t, scheduled.ID, result.ScheduledTransfer.ID)
+	assert.Equal(t, ScheduledTransferExecuted, result.ScheduledTransfer.Status)
+	assert.True(t, result.ScheduledTransfer.ProcessedAt.Valid)
+	assert.Equal(t, result.Transfer.Transfer.ID, result.ScheduledTransfer.TransferID.Int64)
+	assert.Equal(t, int64(900), result.Transfer.FromAccount.Balance)
+
+	// the other transfer is not due yet
+	_, err = testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	arg.Now = later.ExecuteAt
+	result, err = testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, later.ID, result.ScheduledTransfer.ID)
+}
+
+func TestExecuteScheduledTransferTxFailure(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 50)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	now := randomDueTime()
+	cancelDueScheduledTransfers(t, now)
+	scheduled := scheduleTransfer(t, account1, account2, 100, now)
+
+	result, err := testStore.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		Now:     now,
+		Convert: doubleConvert,
+		Retry:   RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, scheduled.ID, result.ScheduledTransfer.ID)
+	assert.Equal(t, ScheduledTransferFailed, result.ScheduledTransfer.Status)
+	assert.Equal(t, ErrInsufficientFunds.Error(), result.ScheduledTransfer.FailureReason.String)
+	assert.False(t, result.ScheduledTransfer.TransferID.Valid)
+	assert.Nil(t, result.Transfer)
+
+	// the failed attempt left nothing behind
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), account.Balance)
+
+	entries, err := testStore.ListEntries(context.Background(), ListEntriesParams{AccountID: account1.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// a failed transfer can no longer be cancelled
+	_, err = testStore.FinishScheduledTransfer(context.Background(), FinishScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: ScheduledTransferCancelled,
+	})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExecuteScheduledTransferTxRetry(t *testing.T) {
+	account1 := createAccountInCurrency(t, util.GBP, 1000)
+	account2 := createAccountInCurrency(t, util.USD, 0)
+
+	now := randomDueTime()
+	cancelDueScheduledTransfers(t, now)
+	scheduled := scheduleTransfer(t, account1, account2, 100, now)
+
+	errUnavailable := errors.New("rate provider unavailable")
+	arg := ExecuteScheduledTransferTxParams{
+		Now: now,
+		Convert: func(ctx context.Context, amount int64, from string, to string) (int64, money.Rate, error) {
+			return 0, money.Rate{}, errUnavailable
+		},
+		Retry: RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+	}
+
+	// an unexpected error is recorded and the transfer waits for its next attempt
+	result, err := testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Transfer)
+
+	retried := result.ScheduledTransfer
+	assert.Equal(t, scheduled.ID, retried.ID)
+	assert.Equal(t, ScheduledTransferPending, retried.Status)
+	assert.Equal(t, int32(1), retried.Attempts)
+	assert.Equal(t, errUnavailable.Error(), retried.LastError.String)
+	assert.Equal(t, now.Add(time.Hour).UTC(), retried.NextAttemptAt.UTC())
+
+	_, err = testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	// out of retries, so it fails with the last error
+	arg.Now = retried.NextAttemptAt
+	result, err = testStore.ExecuteScheduledTransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, scheduled.ID, result.ScheduledTransfer.ID)
+	assert.Equal(t, ScheduledTransferFailed, result.ScheduledTransfer.Status)
+	assert.Equal(t, errUnavailable.Error(), result.ScheduledTransfer.FailureReason.String)
+
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), account.Balance)
+}
+
+func TestExecuteScheduledTransferTxConcurrent(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+
+	now := randomDueTime()
+	cancelDueScheduledTransfers(t, now)
+	n := 5
+	for i := 0; i < n; i++ {
+		scheduleTransfer(t, account1, account2, 10, now)
+	}
+
+	// more schedulers than transfers, as with several replicas running
+	schedulers := 2 * n
+	results := make(chan ExecuteScheduledTransferTxResult)
+	errs := make(chan error)
+	for i := 0; i < schedulers; i++ {
+		go func() {
+			result, err := testStore.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+				Now:     now,
+				Convert: doubleConvert,
+				Retry:   RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+			})
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- result
+		}()
+	}
+
+	executed := make(map[int64]bool)
+	for i := 0; i < schedulers; i++ {
+		select {
+		case result := <-results:
+			assert.Equal(t, ScheduledTransferExecuted, result.ScheduledTransfer.Status)
+			assert.False(t, executed[result.ScheduledTransfer.ID])
+			executed[result.ScheduledTransfer.ID] = true
+		case err := <-errs:
+			assert.ErrorIs(t, err, ErrRecordNotFound)
+		}
+	}
+	assert.Len(t, executed, n)
+
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000-10*n), account.Balance)
+}
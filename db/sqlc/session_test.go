@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T) Session {
+	user := createRandomUser(t)
+
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testStore.CreateSession(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session)
+
+	assert.Equal(t, arg.ID, session.ID)
+	assert.Equal(t, arg.Username, session.Username)
+	assert.Equal(t, arg.RefreshToken, session.RefreshToken)
+	assert.Equal(t, arg.UserAgent, session.UserAgent)
+	assert.Equal(t, arg.ClientIp, session.ClientIp)
+	assert.False(t, session.IsBlocked)
+	assert.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+
+	assert.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t)
+}
+
+func TestGetSession(t *testing.T) {
+	session1 := createRandomSession(t)
+	session2, err := testStore.GetSession(context.Background(), session1.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session2)
+
+	assert.Equal(t, session1.ID, session2.ID)
+	assert.Equal(t, session1.Username, session2.Username)
+	assert.Equal(t, session1.RefreshToken, session2.RefreshToken)
+	assert.Equal(t, session1.IsBlocked, session2.IsBlocked)
+	assert.WithinDuration(t, session1.ExpiresAt, session2.ExpiresAt, time.Second)
+}
+
+func TestBlockSession(t *testing.T) {
+	session1 := createRandomSession(t)
+	session2, err := testStore.BlockSession(context.Background(), session1.ID)
+	assert.NoError(t, err)
+	assert.True(t, session2.IsBlocked)
+}
+
+func TestListActiveSessionsAndBlockUserSessions(t *testing.T) {
+	session1 := createRandomSession(t)
+
+	sessions, err := testStore.ListActiveSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, session1.ID, sessions[0].ID)
+
+	blocked, err := testStore.BlockUserSessions(context.Background(), session1.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), blocked)
+
+	sessions, err = testStore.ListActiveSessions(context.Background(), session1.Username)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/drmanalo/simplebank/recurrence"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StandingOrderActive    = "active"
+	StandingOrderCompleted = "completed"
+	StandingOrderCancelled = "cancelled"
+)
+
+// ErrStandingOrderNotActive is returned when changing a standing order that has completed or been cancelled
+var ErrStandingOrderNotActive = errors.New("standing order is no longer active")
+
+// RetryPolicy decides when a failed occurrence of a standing order, or a scheduled transfer
+// that failed for an unexpected reason, is tried again
+type RetryPolicy struct {
+	// MaxRetries is how many times an attempt is retried before it is given up on
+	MaxRetries int32
+	// Backoff is the wait before the first retry. It doubles with every retry after that, up to maxRetryDelay
+	Backoff time.Duration
+}
+
+// maxRetryDelay is the longest wait between two attempts, however many retries there have been
+const maxRetryDelay = 24 * time.Hour
+
+func (policy RetryPolicy) delay(retry int32) time.Duration {
+	delay := policy.Backoff
+	// doubling stops at the cap, so a large retry count cannot overflow the duration
+	for i := int32(1); i < retry && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Rule is the recurrence rule of the order. Its dates are worked out in the time zone of the order,
+// so a payment keeps its local time of day when the clocks change
+func (order StandingOrder) Rule() (recurrence.Rule, error) {
+	location, err := time.LoadLocation(order.TimeZone)
+	if err != nil {
+		return recurrence.Rule{}, err
+	}
+
+	return recurrence.Rule{
+		Frequency: order.Frequency,
+		Interval:  int(order.Interval),
+		Start:     order.StartAt.In(location),
+	}, nil
+}
+
+// nextOccurrence returns the date of the occurrence the order is up to, past the ones paid and skipped.
+// It returns false once the order has paid MaxOccurrences payments or passed its end date
+func nextOccurrence(order StandingOrder) (time.Time, bool, error) {
+	if order.MaxOccurrences.Valid && order.Occurrences >= order.MaxOccurrences.Int32 {
+		return time.Time{}, false, nil
+	}
+
+	rule, err := order.Rule()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	next := rule.Occurrence(int(order.Occurrences + order.SkippedOccurrences))
+	if order.EndAt.Valid && next.After(order.EndAt.Time) {
+		return time.Time{}, false, nil
+	}
+
+	return next, true, nil
+}
+
+// scheduleNextOccurrence points the order at the occurrence it is up to, or completes it if there is none left
+func scheduleNextOccurrence(order *StandingOrder) error {
+	next, ok, err := nextOccurrence(*order)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		order.Status = StandingOrderCompleted
+		order.NextRunAt = pgtype.Timestamptz{}
+		return nil
+	}
+
+	order.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+	return nil
+}
+
+func saveStandingOrder(ctx context.Context, q *Queries, order StandingOrder) (StandingOrder, error) {
+	return q.UpdateStandingOrder(ctx, UpdateStandingOrderParams{
+		ID:                 order.ID,
+		Amount:             order.Amount,
+		EndAt:              order.EndAt,
+		MaxOccurrences:     order.MaxOccurrences,
+		Occurrences:        order.Occurrences,
+		SkippedOccurrences: order.SkippedOccurrences,
+		NextRunAt:          order.NextRunAt,
+		RetryCount:         order.RetryCount,
+		LastError:          order.LastError,
+		Status:             order.Status,
+	})
+}
@@ -0,0 +1,360 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: standing_order.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelStandingOrder = `-- name: CancelStandingOrder :one
+UPDATE standing_orders
+SET
+  status = 'cancelled',
+  next_run_at = NULL
+WHERE id = $1 AND status = 'active'
+RETURNING id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone
+`
+
+func (q *Queries) CancelStandingOrder(ctx context.Context, id int64) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, cancelStandingOrder, id)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
+
+const claimDueStandingOrder = `-- name: ClaimDueStandingOrder :one
+UPDATE standing_orders
+SET next_run_at = $1
+WHERE id = (
+  SELECT due.id FROM standing_orders due
+  WHERE due.status = 'active' AND due.next_run_at <= $2
+  ORDER BY due.next_run_at, due.id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone
+`
+
+type ClaimDueStandingOrderParams struct {
+	LeaseUntil pgtype.Timestamptz `json:"lease_until"`
+	Now        pgtype.Timestamptz `json:"now"`
+}
+
+// Leases the next active order that is due by pushing its next run back to lease_until.
+// Rows locked by another worker are skipped, so workers running at the same time each claim a different order,
+// and an order whose worker stopped before finishing it falls due again once the lease runs out
+func (q *Queries) ClaimDueStandingOrder(ctx context.Context, arg ClaimDueStandingOrderParams) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, claimDueStandingOrder, arg.LeaseUntil, arg.Now)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
+
+const createStandingOrder = `-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (
+  from_account_id,
+  to_account_id,
+  amount,
+  frequency,
+  "interval",
+  start_at,
+  end_at,
+  max_occurrences,
+  time_zone,
+  next_run_at
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $6
+) RETURNING id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone
+`
+
+type CreateStandingOrderParams struct {
+	FromAccountID  int64              `json:"from_account_id"`
+	ToAccountID    int64              `json:"to_account_id"`
+	Amount         int64              `json:"amount"`
+	Frequency      string             `json:"frequency"`
+	Interval       int32              `json:"interval"`
+	StartAt        time.Time          `json:"start_at"`
+	EndAt          pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences pgtype.Int4        `json:"max_occurrences"`
+	TimeZone       string             `json:"time_zone"`
+}
+
+func (q *Queries) CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, createStandingOrder,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Frequency,
+		arg.Interval,
+		arg.StartAt,
+		arg.EndAt,
+		arg.MaxOccurrences,
+		arg.TimeZone,
+	)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
+
+const getStandingOrder = `-- name: GetStandingOrder :one
+SELECT id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone FROM standing_orders
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, getStandingOrder, id)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
+
+const getStandingOrderForUpdate = `-- name: GetStandingOrderForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone FROM standing_orders
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetStandingOrderForUpdate(ctx context.Context, id int64) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, getStandingOrderForUpdate, id)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
+
+const listStandingOrders = `-- name: ListStandingOrders :many
+SELECT
+  so.id, so.from_account_id, so.to_account_id, so.amount, so.frequency, so.interval, so.start_at, so.end_at, so.max_occurrences, so.occurrences, so.next_run_at, so.retry_count, so.last_error, so.status, so.created_at, so.skipped_occurrences, so.time_zone,
+  a.currency
+FROM standing_orders so
+JOIN accounts a ON a.id = so.from_account_id
+WHERE a.owner = $1
+  AND (so.created_at, so.id) > ($2::timestamptz, $3::bigint)
+ORDER BY so.created_at, so.id
+LIMIT $4
+`
+
+type ListStandingOrdersParams struct {
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	RowLimit       int32     `json:"row_limit"`
+}
+
+type ListStandingOrdersRow struct {
+	StandingOrder StandingOrder `json:"standing_order"`
+	Currency      string        `json:"currency"`
+}
+
+func (q *Queries) ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]ListStandingOrdersRow, error) {
+	rows, err := q.db.Query(ctx, listStandingOrders,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStandingOrdersRow{}
+	for rows.Next() {
+		var i ListStandingOrdersRow
+		if err := rows.Scan(
+			&i.StandingOrder.ID,
+			&i.StandingOrder.FromAccountID,
+			&i.StandingOrder.ToAccountID,
+			&i.StandingOrder.Amount,
+			&i.StandingOrder.Frequency,
+			&i.StandingOrder.Interval,
+			&i.StandingOrder.StartAt,
+			&i.StandingOrder.EndAt,
+			&i.StandingOrder.MaxOccurrences,
+			&i.StandingOrder.Occurrences,
+			&i.StandingOrder.NextRunAt,
+			&i.StandingOrder.RetryCount,
+			&i.StandingOrder.LastError,
+			&i.StandingOrder.Status,
+			&i.StandingOrder.CreatedAt,
+			&i.StandingOrder.SkippedOccurrences,
+			&i.StandingOrder.TimeZone,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateStandingOrder = `-- name: UpdateStandingOrder :one
+UPDATE standing_orders
+SET
+  amount = $1,
+  end_at = $2,
+  max_occurrences = $3,
+  occurrences = $4,
+  skipped_occurrences = $5,
+  next_run_at = $6,
+  retry_count = $7,
+  last_error = $8,
+  status = $9
+WHERE id = $10
+RETURNING id, from_account_id, to_account_id, amount, frequency, interval, start_at, end_at, max_occurrences, occurrences, next_run_at, retry_count, last_error, status, created_at, skipped_occurrences, time_zone
+`
+
+type UpdateStandingOrderParams struct {
+	Amount             int64              `json:"amount"`
+	EndAt              pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences     pgtype.Int4        `json:"max_occurrences"`
+	Occurrences        int32              `json:"occurrences"`
+	SkippedOccurrences int32              `json:"skipped_occurrences"`
+	NextRunAt          pgtype.Timestamptz `json:"next_run_at"`
+	RetryCount         int32              `json:"retry_count"`
+	LastError          pgtype.Text        `json:"last_error"`
+	Status             string             `json:"status"`
+	ID                 int64              `json:"id"`
+}
+
+func (q *Queries) UpdateStandingOrder(ctx context.Context, arg UpdateStandingOrderParams) (StandingOrder, error) {
+	row := q.db.QueryRow(ctx, updateStandingOrder,
+		arg.Amount,
+		arg.EndAt,
+		arg.MaxOccurrences,
+		arg.Occurrences,
+		arg.SkippedOccurrences,
+		arg.NextRunAt,
+		arg.RetryCount,
+		arg.LastError,
+		arg.Status,
+		arg.ID,
+	)
+	var i StandingOrder
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.NextRunAt,
+		&i.RetryCount,
+		&i.LastError,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SkippedOccurrences,
+		&i.TimeZone,
+	)
+	return i, err
+}
@@ -0,0 +1,414 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStandingOrder creates a standing order. Every test runs its orders until they end,
+// so they are never claimed by the tests after it
+func openStandingOrder(t *testing.T, from Account, to Account, arg CreateStandingOrderParams) StandingOrder {
+	arg.FromAccountID = from.ID
+	arg.ToAccountID = to.ID
+	if arg.TimeZone == "" {
+		arg.TimeZone = "UTC"
+	}
+
+	order, err := testStore.CreateStandingOrderTx(context.Background(), CreateStandingOrderTxParams{
+		CreateStandingOrderParams: arg,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StandingOrderActive, order.Status)
+	assert.Equal(t, arg.StartAt.UTC(), order.NextRunAt.Time.UTC())
+	assert.Equal(t, arg.TimeZone, order.TimeZone)
+	return order
+}
+
+// testRetry leases a claimed order for an hour, longer than any test takes
+var testRetry = RetryPolicy{Backoff: time.Hour}
+
+// cancelDueStandingOrders cancels the active orders that other tests and earlier runs left
+// due within a year of now, so the worker claims only the ones the calling test opens
+func cancelDueStandingOrders(t *testing.T, now time.Time) {
+	_, err := testStore.(*SLQStore).connPool.Exec(context.Background(),
+		"UPDATE standing_orders SET status = 'cancelled', next_run_at = NULL WHERE status = 'active' AND next_run_at <= $1",
+		now.AddDate(1, 0, 0))
+	assert.NoError(t, err)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 100, Backoff: time.Hour}
+
+	assert.Equal(t, time.Hour, policy.delay(1))
+	assert.Equal(t, 2*time.Hour, policy.delay(2))
+	assert.Equal(t, 16*time.Hour, policy.delay(5))
+	// shifting by the retry count would overflow long before this
+	assert.Equal(t, maxRetryDelay, policy.delay(6))
+	assert.Equal(t, maxRetryDelay, policy.delay(100))
+
+	policy.Backoff = 48 * time.Hour
+	assert.Equal(t, maxRetryDelay, policy.delay(1))
+}
+
+func TestNextOccurrence(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// 9:00 in London is 9:00 UTC in winter and 8:00 UTC in summer
+	order := StandingOrder{
+		Frequency:      "monthly",
+		Interval:       1,
+		StartAt:        time.Date(2027, time.March, 1, 9, 0, 0, 0, time.UTC),
+		MaxOccurrences: pgtype.Int4{Int32: 2, Valid: true},
+		TimeZone:       london.String(),
+	}
+
+	next, ok, err := nextOccurrence(order)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2027, time.March, 1, 9, 0, 0, 0, london), next)
+
+	order.Occurrences = 1
+	next, ok, err = nextOccurrence(order)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2027, time.April, 1, 8, 0, 0, 0, time.UTC), next.UTC())
+
+	// a skipped occurrence moves the order on without using up MaxOccurrences
+	order.SkippedOccurrences = 1
+	next, ok, err = nextOccurrence(order)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2027, time.May, 1, 9, 0, 0, 0, london), next)
+
+	order.Occurrences = 2
+	_, ok, err = nextOccurrence(order)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	order.Occurrences = 0
+	order.TimeZone = "Mars/Olympus_Mons"
+	_, _, err = nextOccurrence(order)
+	assert.Error(t, err)
+}
+
+func TestExecuteStandingOrderTx(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	// rent on the 31st falls on the last day of shorter months
+	due := randomDueTime()
+	start := time.Date(due.Year(), time.January, 31, due.Hour(), due.Minute(), due.Second(), 0, time.UTC)
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:         100,
+		Frequency:      "monthly",
+		Interval:       1,
+		StartAt:        start,
+		MaxOccurrences: pgtype.Int4{Int32: 3, Valid: true},
+	})
+
+	arg := ExecuteStandingOrderTxParams{Now: start.Add(-time.Second), Convert: doubleConvert, Retry: testRetry}
+	_, err := testStore.ExecuteStandingOrderTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	lastOfFebruary := time.Date(start.Year(), time.March, 0, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	expected := []time.Time{lastOfFebruary, start.AddDate(0, 2, 0), {}}
+
+	for i, next := range expected {
+		arg.Now = order.NextRunAt.Time
+		result, err := testStore.ExecuteStandingOrderTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.NotNil(t, result.Transfer)
+
+		order = result.StandingOrder
+		assert.Equal(t, int32(i+1), order.Occurrences)
+		assert.Equal(t, next, order.NextRunAt.Time.UTC())
+		assert.Equal(t, order.ID, result.Transfer.Transfer.StandingOrderID.Int64)
+		assert.Equal(t, int64(100), result.Transfer.Transfer.Amount)
+	}
+
+	assert.Equal(t, StandingOrderCompleted, order.Status)
+	assert.False(t, order.NextRunAt.Valid)
+
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(700), account.Balance)
+
+	// a completed order is never due again
+	arg.Now = start.AddDate(1, 0, 0)
+	_, err = testStore.ExecuteStandingOrderTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExecuteStandingOrderTxRetry(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 50)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	start := randomDueTime().UTC()
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:         100,
+		Frequency:      "daily",
+		Interval:       1,
+		StartAt:        start,
+		MaxOccurrences: pgtype.Int4{Int32: 2, Valid: true},
+	})
+
+	arg := ExecuteStandingOrderTxParams{
+		Now:     start,
+		Convert: doubleConvert,
+		Retry:   RetryPolicy{MaxRetries: 2, Backoff: time.Hour},
+	}
+
+	// the backoff doubles with each retry
+	for retry, wait := range []time.Duration{time.Hour, 2 * time.Hour} {
+		result, err := testStore.ExecuteStandingOrderTx(context.Background(), arg)
+		assert.NoError(t, err)
+		assert.Nil(t, result.Transfer)
+
+		order = result.StandingOrder
+		assert.Equal(t, int32(retry+1), order.RetryCount)
+		assert.Equal(t, ErrInsufficientFunds.Error(), order.LastError.String)
+		assert.Equal(t, arg.Now.Add(wait).UTC(), order.NextRunAt.Time.UTC())
+		assert.Zero(t, order.Occurrences)
+
+		arg.Now = order.NextRunAt.Time
+	}
+
+	// out of retries, so the occurrence is skipped and the next one is waited for
+	result, err := testStore.ExecuteStandingOrderTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Transfer)
+
+	order = result.StandingOrder
+	assert.Zero(t, order.Occurrences)
+	assert.Equal(t, int32(1), order.SkippedOccurrences)
+	assert.Zero(t, order.RetryCount)
+	assert.Equal(t, start.AddDate(0, 0, 1), order.NextRunAt.Time.UTC())
+	assert.Equal(t, StandingOrderActive, order.Status)
+
+	// the failed attempts left nothing behind
+	entries, err := testStore.ListEntries(context.Background(), ListEntriesParams{AccountID: account1.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = testStore.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account1.ID,
+		Amount:    150,
+		Reason:    "test funds",
+	})
+	assert.NoError(t, err)
+
+	// the skipped occurrence doesn't count towards MaxOccurrences, so two more are paid
+	for i, next := range []time.Time{start.AddDate(0, 0, 2), {}} {
+		arg.Now = order.NextRunAt.Time
+		result, err = testStore.ExecuteStandingOrderTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.NotNil(t, result.Transfer)
+
+		order = result.StandingOrder
+		assert.Equal(t, int32(i+1), order.Occurrences)
+		assert.Equal(t, int32(1), order.SkippedOccurrences)
+		assert.False(t, order.LastError.Valid)
+		assert.Equal(t, next, order.NextRunAt.Time.UTC())
+	}
+	assert.Equal(t, StandingOrderCompleted, order.Status)
+}
+
+func TestUpdateStandingOrderTx(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	start := randomDueTime().UTC()
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:    100,
+		Frequency: "weekly",
+		Interval:  2,
+		StartAt:   start,
+	})
+
+	// ending the order on its first occurrence still pays that one
+	updated, err := testStore.UpdateStandingOrderTx(context.Background(), UpdateStandingOrderTxParams{
+		ID:     order.ID,
+		Amount: pgtype.Int8{Int64: 200, Valid: true},
+		EndAt:  pgtype.Timestamptz{Time: start, Valid: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), updated.Amount)
+	assert.Equal(t, StandingOrderActive, updated.Status)
+	assert.Equal(t, start, updated.NextRunAt.Time.UTC())
+
+	result, err := testStore.ExecuteStandingOrderTx(context.Background(), ExecuteStandingOrderTxParams{
+		Now:     start,
+		Convert: doubleConvert,
+		Retry:   testRetry,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	assert.Equal(t, order.ID, result.StandingOrder.ID)
+	assert.Equal(t, int64(200), result.Transfer.Transfer.Amount)
+	assert.Equal(t, StandingOrderCompleted, result.StandingOrder.Status)
+
+	_, err = testStore.UpdateStandingOrderTx(context.Background(), UpdateStandingOrderTxParams{
+		ID:     order.ID,
+		Amount: pgtype.Int8{Int64: 300, Valid: true},
+	})
+	assert.ErrorIs(t, err, ErrStandingOrderNotActive)
+}
+
+func TestUpdateStandingOrderTxCompletes(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	start := randomDueTime().UTC()
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:    100,
+		Frequency: "daily",
+		Interval:  1,
+		StartAt:   start,
+	})
+
+	// an end date before the next occurrence leaves nothing to pay
+	updated, err := testStore.UpdateStandingOrderTx(context.Background(), UpdateStandingOrderTxParams{
+		ID:    order.ID,
+		EndAt: pgtype.Timestamptz{Time: start.Add(-time.Minute), Valid: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StandingOrderCompleted, updated.Status)
+	assert.False(t, updated.NextRunAt.Valid)
+}
+
+func TestCancelStandingOrder(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	start := randomDueTime().UTC()
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:    100,
+		Frequency: "monthly",
+		Interval:  1,
+		StartAt:   start,
+	})
+
+	cancelled, err := testStore.CancelStandingOrder(context.Background(), order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StandingOrderCancelled, cancelled.Status)
+	assert.False(t, cancelled.NextRunAt.Valid)
+
+	_, err = testStore.CancelStandingOrder(context.Background(), order.ID)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.ExecuteStandingOrderTx(context.Background(), ExecuteStandingOrderTxParams{
+		Now:     start,
+		Convert: doubleConvert,
+		Retry:   testRetry,
+	})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExecuteStandingOrderTxUnexpectedError(t *testing.T) {
+	account1 := createAccountInCurrency(t, util.GBP, 1000)
+	account2 := createAccountInCurrency(t, util.USD, 0)
+
+	start := randomDueTime().UTC()
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:    100,
+		Frequency: "daily",
+		Interval:  1,
+		StartAt:   start,
+	})
+
+	errUnavailable := errors.New("rate provider unavailable")
+	result, err := testStore.ExecuteStandingOrderTx(context.Background(), ExecuteStandingOrderTxParams{
+		Now: start,
+		Convert: func(ctx context.Context, amount int64, from string, to string) (int64, money.Rate, error) {
+			return 0, money.Rate{}, errUnavailable
+		},
+		Retry: RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Transfer)
+
+	// the error is recorded and the occurrence retried, rather than blocking the orders after it
+	order = result.StandingOrder
+	assert.Equal(t, int32(1), order.RetryCount)
+	assert.Equal(t, errUnavailable.Error(), order.LastError.String)
+	assert.Equal(t, start.Add(time.Hour), order.NextRunAt.Time.UTC())
+	assert.Zero(t, order.Occurrences)
+
+	// the retry converts the amount
+	result, err = testStore.ExecuteStandingOrderTx(context.Background(), ExecuteStandingOrderTxParams{
+		Now:     order.NextRunAt.Time,
+		Convert: doubleConvert,
+		Retry:   RetryPolicy{MaxRetries: 1, Backoff: time.Hour},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	assert.Equal(t, int64(200), result.Transfer.Transfer.ToAmount)
+	assert.Equal(t, int32(1), result.StandingOrder.Occurrences)
+	assert.Equal(t, start.AddDate(0, 0, 1), result.StandingOrder.NextRunAt.Time.UTC())
+
+	_, err = testStore.CancelStandingOrder(context.Background(), order.ID)
+	assert.NoError(t, err)
+}
+
+func TestExecuteStandingOrderTxConcurrent(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	start := randomDueTime().UTC()
+	cancelDueStandingOrders(t, start)
+	order := openStandingOrder(t, account1, account2, CreateStandingOrderParams{
+		Amount:         10,
+		Frequency:      "daily",
+		Interval:       1,
+		StartAt:        start,
+		MaxOccurrences: pgtype.Int4{Int32: 1, Valid: true},
+	})
+
+	// several workers see the same order fall due, and only one of them pays it
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testStore.ExecuteStandingOrderTx(context.Background(), ExecuteStandingOrderTxParams{
+				Now:     start,
+				Convert: doubleConvert,
+				Retry:   testRetry,
+			})
+			errs <- err
+		}()
+	}
+
+	paid := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			paid++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	}
+	assert.Equal(t, 1, paid)
+
+	order, err := testStore.GetStandingOrder(context.Background(), order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), order.Occurrences)
+	assert.Equal(t, StandingOrderCompleted, order.Status)
+
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(990), account.Balance)
+}
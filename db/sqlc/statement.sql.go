@@ -0,0 +1,111 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: statement.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getStatementOpeningBalance = `-- name: GetStatementOpeningBalance :one
+SELECT (a.balance - COALESCE((
+  SELECT SUM(e.amount)
+  FROM entries e
+  WHERE e.account_id = a.id
+    AND e.created_at >= $1
+), 0))::bigint AS opening_balance
+FROM accounts a
+WHERE a.id = $2
+`
+
+type GetStatementOpeningBalanceParams struct {
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	AccountID int64              `json:"account_id"`
+}
+
+func (q *Queries) GetStatementOpeningBalance(ctx context.Context, arg GetStatementOpeningBalanceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getStatementOpeningBalance, arg.FromTime, arg.AccountID)
+	var opening_balance int64
+	err := row.Scan(&opening_balance)
+	return opening_balance, err
+}
+
+const listStatementLines = `-- name: ListStatementLines :many
+WITH opening AS (
+  SELECT (a.balance - COALESCE((
+    SELECT SUM(e.amount)
+    FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= $2
+  ), 0))::bigint AS balance
+  FROM accounts a
+  WHERE a.id = $1
+)
+SELECT
+  e.id AS entry_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner,
+  (opening.balance + SUM(e.amount) OVER (ORDER BY e.created_at, e.id))::bigint AS running_balance
+FROM entries e
+CROSS JOIN opening
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+  WHEN t.from_account_id = e.account_id THEN t.to_account_id
+  ELSE t.from_account_id
+END
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementLinesParams struct {
+	AccountID int64              `json:"account_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+}
+
+type ListStatementLinesRow struct {
+	EntryID               int64              `json:"entry_id"`
+	Amount                int64              `json:"amount"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	TransferID            pgtype.Int8        `json:"transfer_id"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	CounterpartyOwner     pgtype.Text        `json:"counterparty_owner"`
+	RunningBalance        int64              `json:"running_balance"`
+}
+
+func (q *Queries) ListStatementLines(ctx context.Context, arg ListStatementLinesParams) ([]ListStatementLinesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementLines, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementLinesRow{}
+	for rows.Next() {
+		var i ListStatementLinesRow
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
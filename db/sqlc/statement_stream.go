@@ -0,0 +1,33 @@
+package db
+
+import "context"
+
+// StreamStatementLines runs the ListStatementLines query but calls fn for each row as it is read,
+// so exporting a long period never holds the whole statement in memory. It stops at the first error from fn
+func (q *Queries) StreamStatementLines(ctx context.Context, arg ListStatementLinesParams, fn func(ListStatementLinesRow) error) error {
+	rows, err := q.db.Query(ctx, listStatementLines, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i ListStatementLinesRow
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
+			&i.RunningBalance,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListStatementLines(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+	from := time.Now().Add(-time.Second)
+
+	result1, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	assert.NoError(t, err)
+
+	result2, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        30,
+	})
+	assert.NoError(t, err)
+
+	to := time.Now().Add(time.Second)
+	lines, err := testStore.ListStatementLines(context.Background(), ListStatementLinesParams{
+		AccountID: account1.ID,
+		FromTime:  pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, result1.FromEntry.ID, lines[0].EntryID)
+	assert.Equal(t, int64(-100), lines[0].Amount)
+	assert.Equal(t, int64(900), lines[0].RunningBalance)
+	assert.Equal(t, result1.Transfer.ID, lines[0].TransferID.Int64)
+	assert.Equal(t, account2.ID, lines[0].CounterpartyAccountID.Int64)
+	assert.Equal(t, account2.Owner, lines[0].CounterpartyOwner.String)
+
+	assert.Equal(t, result2.ToEntry.ID, lines[1].EntryID)
+	assert.Equal(t, int64(30), lines[1].Amount)
+	assert.Equal(t, int64(930), lines[1].RunningBalance)
+	assert.Equal(t, result2.Transfer.ID, lines[1].TransferID.Int64)
+	assert.Equal(t, account2.ID, lines[1].CounterpartyAccountID.Int64)
+
+	var streamed []ListStatementLinesRow
+	err = testStore.ExportStatementTx(context.Background(), ExportStatementTxParams{
+		AccountID: account1.ID,
+		FromTime:  pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: to, Valid: true},
+		Write: func(balances StatementBalances, streamLines func(fn func(ListStatementLinesRow) error) error) error {
+			assert.Equal(t, StatementBalances{OpeningBalance: 1000, ClosingBalance: 930}, balances)
+			return streamLines(func(line ListStatementLinesRow) error {
+				streamed = append(streamed, line)
+				return nil
+			})
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, lines, streamed)
+
+	opening, err := testStore.GetStatementOpeningBalance(context.Background(), GetStatementOpeningBalanceParams{
+		FromTime:  pgtype.Timestamptz{Time: from, Valid: true},
+		AccountID: account1.ID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), opening)
+
+	opening, err = testStore.GetStatementOpeningBalance(context.Background(), GetStatementOpeningBalanceParams{
+		FromTime:  pgtype.Timestamptz{Time: to, Valid: true},
+		AccountID: account1.ID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(930), opening)
+}
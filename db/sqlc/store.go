@@ -10,6 +10,20 @@ import (
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (ScheduledTransfer, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	CreateStandingOrderTx(ctx context.Context, arg CreateStandingOrderTxParams) (StandingOrder, error)
+	UpdateStandingOrderTx(ctx context.Context, arg UpdateStandingOrderTxParams) (StandingOrder, error)
+	ExecuteStandingOrderTx(ctx context.Context, arg ExecuteStandingOrderTxParams) (ExecuteStandingOrderTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	DisableUserTx(ctx context.Context, arg DisableUserTxParams) (DisableUserTxResult, error)
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	ExportStatementTx(ctx context.Context, arg ExportStatementTxParams) error
+	CheckLedger(ctx context.Context, arg CheckLedgerParams) (LedgerReport, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -2,15 +2,22 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	// both accounts must be able to cover every concurrent debit
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	n := 5
@@ -60,6 +67,7 @@ func TestTransferTx(t *testing.T) {
 		assert.NotEmpty(t, fromEntry)
 		assert.Equal(t, account1.ID, fromEntry.AccountID)
 		assert.Equal(t, -amount, fromEntry.Amount)
+		assert.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		assert.NotZero(t, fromEntry.ID)
 		assert.NotZero(t, fromEntry.CreatedAt)
 
@@ -70,6 +78,7 @@ func TestTransferTx(t *testing.T) {
 		assert.NotEmpty(t, toEntry)
 		assert.Equal(t, account2.ID, toEntry.AccountID)
 		assert.Equal(t, amount, toEntry.Amount)
+		assert.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		assert.NotZero(t, toEntry.ID)
 		assert.NotZero(t, toEntry.CreatedAt)
 
@@ -114,8 +123,9 @@ func TestTransferTx(t *testing.T) {
 }
 
 func TestTransferTxDeadlock(t *testing.T) {
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	// both accounts must be able to cover every concurrent debit
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	n := 10
@@ -158,3 +168,432 @@ func TestTransferTxDeadlock(t *testing.T) {
 	assert.Equal(t, account1.Balance, updateAccount1.Balance)
 	assert.Equal(t, account2.Balance, updateAccount2.Balance)
 }
+
+func TestCreateUserTx(t *testing.T) {
+	arg := CreateUserTxParams{
+		CreateUserParams: randomCreateUserParams(t),
+	}
+
+	var callbackUser User
+	arg.AfterCreate = func(user User) error {
+		callbackUser = user
+		return nil
+	}
+
+	result, err := testStore.CreateUserTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.Username, result.User.Username)
+	assert.Equal(t, result.User, callbackUser)
+
+	user, err := testStore.GetUser(context.Background(), arg.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, result.User.Username, user.Username)
+}
+
+func TestCreateUserTxRollback(t *testing.T) {
+	arg := CreateUserTxParams{
+		CreateUserParams: randomCreateUserParams(t),
+		AfterCreate: func(user User) error {
+			return errors.New("after create failed")
+		},
+	}
+
+	_, err := testStore.CreateUserTx(context.Background(), arg)
+	assert.EqualError(t, err, "after create failed")
+
+	// the user must not be persisted when the callback fails
+	_, err = testStore.GetUser(context.Background(), arg.Username)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccount(t)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		IdempotencyKey: &IdempotencyKeyParams{
+			Key:            util.RandomString(16),
+			Username:       account1.Owner,
+			RequestPath:    "/transfers",
+			RequestHash:    util.RandomString(64),
+			ResponseStatus: 201,
+			ExpiresAt:      time.Now().Add(time.Hour),
+		},
+	}
+
+	result, err := testStore.TransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+
+	idempotencyKey, err := testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.IdempotencyKey.Username,
+		Key:      arg.IdempotencyKey.Key,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, arg.IdempotencyKey.RequestHash, idempotencyKey.RequestHash)
+
+	var stored TransferTxResult
+	assert.NoError(t, json.Unmarshal(idempotencyKey.ResponseBody, &stored))
+	assert.Equal(t, result.Transfer.ID, stored.Transfer.ID)
+
+	// a second transfer with the same live key is rolled back
+	_, err = testStore.TransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInUse)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result.FromAccount.Balance, updatedAccount1.Balance)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccount(t)
+
+	n := 20
+	amount := int64(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+
+	// only the transfers covered by the balance go through
+	assert.Equal(t, 10, succeeded)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), updatedAccount1.Balance)
+
+	updatedAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account2.Balance+int64(succeeded)*amount, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 0)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	assert.NoError(t, err)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-50), result.FromAccount.Balance)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the failed transfer leaves no trace
+	transfers, err := testStore.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID: account1.ID,
+		RowLimit:  5,
+		RowOffset: 0,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      125,
+		ExchangeRate:  money.MustParseRate("1.25"),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(100), result.Transfer.Amount)
+	assert.Equal(t, int64(125), result.Transfer.ToAmount)
+	assert.Equal(t, money.MustParseRate("1.25"), result.Transfer.ExchangeRate)
+
+	// each entry is recorded in the currency of its account
+	assert.Equal(t, int64(-100), result.FromEntry.Amount)
+	assert.Equal(t, int64(125), result.ToEntry.Amount)
+
+	assert.Equal(t, int64(900), result.FromAccount.Balance)
+	assert.Equal(t, int64(1125), result.ToAccount.Balance)
+}
+
+func TestTransferTxAccountNotActive(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: AccountStatusFrozen,
+	})
+	assert.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+
+	var statusErr *AccountStatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, account2.ID, statusErr.AccountID)
+	assert.Equal(t, AccountStatusFrozen, statusErr.Status)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestChangeAccountStatusTx(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 0)
+	assert.Equal(t, AccountStatusActive, account.Status)
+
+	changeStatus := func(status string) (Account, error) {
+		result, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+			AccountID: account.ID,
+			Status:    status,
+		})
+		return result.Account, err
+	}
+
+	updated, err := changeStatus(AccountStatusFrozen)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountStatusFrozen, updated.Status)
+
+	// a frozen account stays open until it is unfrozen
+	_, err = changeStatus(AccountStatusClosed)
+	assert.ErrorIs(t, err, ErrInvalidStatusChange)
+
+	_, err = changeStatus(AccountStatusFrozen)
+	assert.ErrorIs(t, err, ErrInvalidStatusChange)
+
+	updated, err = changeStatus(AccountStatusActive)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountStatusActive, updated.Status)
+
+	updated, err = changeStatus(AccountStatusClosed)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountStatusClosed, updated.Status)
+
+	_, err = changeStatus(AccountStatusActive)
+	assert.ErrorIs(t, err, ErrInvalidStatusChange)
+}
+
+func TestChangeAccountStatusTxCloseWithSweep(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	arg := ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+	}
+
+	_, err := testStore.ChangeAccountStatusTx(context.Background(), arg)
+	assert.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	arg.SweepAccountID = account2.ID
+	result, err := testStore.ChangeAccountStatusTx(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountStatusClosed, result.Account.Status)
+	assert.Equal(t, int64(0), result.Account.Balance)
+
+	require.NotNil(t, result.Sweep)
+	assert.Equal(t, int64(100), result.Sweep.Transfer.Amount)
+	assert.Equal(t, int64(100), result.Sweep.ToAccount.Balance)
+
+	// the closed account cannot take part in any more transfers
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	var statusErr *AccountStatusError
+	assert.ErrorAs(t, err, &statusErr)
+}
+
+func TestAdjustBalanceTx(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 0)
+
+	result, err := testStore.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    500,
+		Reason:    "opening deposit",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), result.Account.Balance)
+	assert.Equal(t, "opening deposit", result.Adjustment.Reason)
+	assert.Equal(t, result.Adjustment.ID, result.Entry.AdjustmentID.Int64)
+	assert.Equal(t, int64(500), result.Entry.Amount)
+
+	_, err = testStore.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    -501,
+		Reason:    "too much",
+	})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the adjustment entry is accounted for, so the ledger still balances
+	report, err := testStore.CheckLedger(context.Background(), CheckLedgerParams{BatchSize: 1000})
+	assert.NoError(t, err)
+	for _, mismatch := range report.BalanceMismatches {
+		assert.NotEqual(t, account.ID, mismatch.AccountID)
+	}
+	assert.NotContains(t, report.OrphanEntries, result.Entry)
+
+	_, err = testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID:      account.ID,
+		Status:         AccountStatusClosed,
+		SweepAccountID: createRandomAccount(t).ID,
+	})
+	assert.NoError(t, err)
+
+	_, err = testStore.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    1,
+		Reason:    "closed",
+	})
+	var statusErr *AccountStatusError
+	assert.ErrorAs(t, err, &statusErr)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+
+	original, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      125,
+		ExchangeRate:  money.MustParseRate("1.25"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, original.ReversalStatus)
+	assert.Equal(t, ReversalStatus{Status: ReversalStatusNone, RemainingAmount: 125}, *original.ReversalStatus)
+
+	partial, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     50,
+		Reason:     ReversalReasonRequestedByCustomer,
+	})
+	assert.NoError(t, err)
+
+	// the reversal runs the other way and is converted back at the original rate
+	reversal := partial.Reversal.Transfer
+	assert.Equal(t, account2.ID, reversal.FromAccountID)
+	assert.Equal(t, account1.ID, reversal.ToAccountID)
+	assert.Equal(t, int64(50), reversal.Amount)
+	assert.Equal(t, int64(40), reversal.ToAmount)
+	assert.Equal(t, original.Transfer.ID, reversal.ReversesTransferID.Int64)
+	assert.Equal(t, ReversalReasonRequestedByCustomer, reversal.ReversalReason.String)
+	assert.Nil(t, partial.Reversal.ReversalStatus)
+	assert.Equal(t, ReversalStatus{Status: ReversalStatusPartial, ReversedAmount: 50, RemainingAmount: 75}, partial.OriginalStatus)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     76,
+		Reason:     ReversalReasonRequestedByCustomer,
+	})
+	assert.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+		Reason:     ReversalReasonDuplicate,
+	})
+	assert.ErrorIs(t, err, ErrTransferIsReversal)
+
+	// the rest is reversed in full, so the from account gets back exactly what it sent
+	rest, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Reason:     ReversalReasonProcessingError,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(75), rest.Reversal.Transfer.Amount)
+	assert.Equal(t, int64(60), rest.Reversal.Transfer.ToAmount)
+	assert.Equal(t, ReversalStatus{Status: ReversalStatusFull, ReversedAmount: 125}, rest.OriginalStatus)
+	assert.Equal(t, int64(1000), rest.Reversal.ToAccount.Balance)
+	assert.Equal(t, int64(1000), rest.Reversal.FromAccount.Balance)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     1,
+		Reason:     ReversalReasonDuplicate,
+	})
+	assert.ErrorIs(t, err, ErrTransferReversed)
+
+	details, err := testStore.GetTransferDetails(context.Background(), original.Transfer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ReversalStatusFull, details.ReversalStatus().Status)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
+
+	original, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	assert.NoError(t, err)
+
+	// only three reversals of 30 fit into 100
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: original.Transfer.ID,
+				Amount:     30,
+				Reason:     ReversalReasonDuplicate,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrReversalExceedsTransfer)
+	}
+	assert.Equal(t, 3, succeeded)
+
+	details, err := testStore.GetTransferDetails(context.Background(), original.Transfer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), details.ReversedAmount)
+}
@@ -7,26 +7,48 @@ package db
 
 import (
 	"context"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   amount,
   from_account_id,
-  to_account_id
+  to_account_id,
+  to_amount,
+  exchange_rate,
+  reverses_transfer_id,
+  reversal_reason,
+  standing_order_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reverses_transfer_id, reversal_reason, standing_order_id
 `
 
 type CreateTransferParams struct {
-	Amount        int64 `json:"amount"`
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
+	Amount             int64       `json:"amount"`
+	FromAccountID      int64       `json:"from_account_id"`
+	ToAccountID        int64       `json:"to_account_id"`
+	ToAmount           int64       `json:"to_amount"`
+	ExchangeRate       money.Rate  `json:"exchange_rate"`
+	ReversesTransferID pgtype.Int8 `json:"reverses_transfer_id"`
+	ReversalReason     pgtype.Text `json:"reversal_reason"`
+	StandingOrderID    pgtype.Int8 `json:"standing_order_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.Amount, arg.FromAccountID, arg.ToAccountID)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.Amount,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.ReversesTransferID,
+		arg.ReversalReason,
+		arg.StandingOrderID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +56,17 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversesTransferID,
+		&i.ReversalReason,
+		&i.StandingOrderID,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reverses_transfer_id, reversal_reason, standing_order_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,34 +79,125 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversesTransferID,
+		&i.ReversalReason,
+		&i.StandingOrderID,
+	)
+	return i, err
+}
+
+const getTransferDetails = `-- name: GetTransferDetails :one
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.reverses_transfer_id, t.reversal_reason, t.standing_order_id,
+  fa.owner AS from_owner,
+  fa.currency AS from_currency,
+  ta.owner AS to_owner,
+  ta.currency AS to_currency,
+  (SELECT COALESCE(SUM(r.amount), 0) FROM transfers r WHERE r.reverses_transfer_id = t.id)::bigint AS reversed_amount
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE t.id = $1 LIMIT 1
+`
+
+type GetTransferDetailsRow struct {
+	Transfer       Transfer `json:"transfer"`
+	FromOwner      string   `json:"from_owner"`
+	FromCurrency   string   `json:"from_currency"`
+	ToOwner        string   `json:"to_owner"`
+	ToCurrency     string   `json:"to_currency"`
+	ReversedAmount int64    `json:"reversed_amount"`
+}
+
+func (q *Queries) GetTransferDetails(ctx context.Context, id int64) (GetTransferDetailsRow, error) {
+	row := q.db.QueryRow(ctx, getTransferDetails, id)
+	var i GetTransferDetailsRow
+	err := row.Scan(
+		&i.Transfer.ID,
+		&i.Transfer.FromAccountID,
+		&i.Transfer.ToAccountID,
+		&i.Transfer.Amount,
+		&i.Transfer.CreatedAt,
+		&i.Transfer.ToAmount,
+		&i.Transfer.ExchangeRate,
+		&i.Transfer.ReversesTransferID,
+		&i.Transfer.ReversalReason,
+		&i.Transfer.StandingOrderID,
+		&i.FromOwner,
+		&i.FromCurrency,
+		&i.ToOwner,
+		&i.ToCurrency,
+		&i.ReversedAmount,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reverses_transfer_id, reversal_reason, standing_order_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversesTransferID,
+		&i.ReversalReason,
+		&i.StandingOrderID,
+	)
+	return i, err
+}
+
+const getTransferReversedAmounts = `-- name: GetTransferReversedAmounts :one
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS reversed_amount,
+  COALESCE(SUM(to_amount), 0)::bigint AS reversed_to_amount
+FROM transfers
+WHERE reverses_transfer_id = $1::bigint
+`
+
+type GetTransferReversedAmountsRow struct {
+	ReversedAmount   int64 `json:"reversed_amount"`
+	ReversedToAmount int64 `json:"reversed_to_amount"`
+}
+
+// Sums the reversals of a transfer. Amount is debited from the original to account
+// and to_amount credited back to the original from account
+func (q *Queries) GetTransferReversedAmounts(ctx context.Context, transferID int64) (GetTransferReversedAmountsRow, error) {
+	row := q.db.QueryRow(ctx, getTransferReversedAmounts, transferID)
+	var i GetTransferReversedAmountsRow
+	err := row.Scan(&i.ReversedAmount, &i.ReversedToAmount)
+	return i, err
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reverses_transfer_id, reversal_reason, standing_order_id FROM transfers
+WHERE
     from_account_id = $1 OR
-    to_account_id = $2
+    to_account_id = $1
 ORDER BY id
 LIMIT $3
-OFFSET $4
+OFFSET $2
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID int64 `json:"account_id"`
+	RowOffset int32 `json:"row_offset"`
+	RowLimit  int32 `json:"row_limit"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.Query(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
-	)
+	rows, err := q.db.Query(ctx, listTransfers, arg.AccountID, arg.RowOffset, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +211,225 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversesTransferID,
+			&i.ReversalReason,
+			&i.StandingOrderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfersNewestFirst = `-- name: ListUserTransfersNewestFirst :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.reverses_transfer_id, t.reversal_reason, t.standing_order_id,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    ($1::varchar IS DISTINCT FROM 'incoming'
+      AND fa.owner = $2
+      AND ($3::bigint IS NULL OR t.from_account_id = $3))
+    OR
+    ($1::varchar IS DISTINCT FROM 'outgoing'
+      AND ta.owner = $2
+      AND ($3::bigint IS NULL OR t.to_account_id = $3))
+  )
+  AND (
+    $4::varchar IS NULL
+    OR
+    (fa.currency = $4
+      AND ($5::bigint IS NULL OR t.amount >= $5)
+      AND ($6::bigint IS NULL OR t.amount <= $6))
+    OR
+    (ta.currency = $4
+      AND ($5::bigint IS NULL OR t.to_amount >= $5)
+      AND ($6::bigint IS NULL OR t.to_amount <= $6))
+  )
+  AND ($7::timestamptz IS NULL OR t.created_at >= $7)
+  AND ($8::timestamptz IS NULL OR t.created_at < $8)
+  AND (t.created_at, t.id) < (
+    COALESCE($9::timestamptz, 'infinity'),
+    COALESCE($10::bigint, 9223372036854775807)
+  )
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $11
+`
+
+type ListUserTransfersNewestFirstParams struct {
+	Direction      pgtype.Text        `json:"direction"`
+	Owner          string             `json:"owner"`
+	AccountID      pgtype.Int8        `json:"account_id"`
+	Currency       pgtype.Text        `json:"currency"`
+	MinAmount      pgtype.Int8        `json:"min_amount"`
+	MaxAmount      pgtype.Int8        `json:"max_amount"`
+	FromTime       pgtype.Timestamptz `json:"from_time"`
+	ToTime         pgtype.Timestamptz `json:"to_time"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.Int8        `json:"after_id"`
+	RowLimit       int32              `json:"row_limit"`
+}
+
+type ListUserTransfersNewestFirstRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+// Lists the transfers the owner sent or received, optionally narrowed by the filters.
+// Direction is from the owner's side, so a transfer between two of their own accounts is both.
+// The amount range applies to whichever leg of the transfer is in the filter currency.
+// Without a cursor the page starts at the newest transfer
+func (q *Queries) ListUserTransfersNewestFirst(ctx context.Context, arg ListUserTransfersNewestFirstParams) ([]ListUserTransfersNewestFirstRow, error) {
+	rows, err := q.db.Query(ctx, listUserTransfersNewestFirst,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.Currency,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserTransfersNewestFirstRow{}
+	for rows.Next() {
+		var i ListUserTransfersNewestFirstRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.ExchangeRate,
+			&i.Transfer.ReversesTransferID,
+			&i.Transfer.ReversalReason,
+			&i.Transfer.StandingOrderID,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfersOldestFirst = `-- name: ListUserTransfersOldestFirst :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.reverses_transfer_id, t.reversal_reason, t.standing_order_id,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    ($1::varchar IS DISTINCT FROM 'incoming'
+      AND fa.owner = $2
+      AND ($3::bigint IS NULL OR t.from_account_id = $3))
+    OR
+    ($1::varchar IS DISTINCT FROM 'outgoing'
+      AND ta.owner = $2
+      AND ($3::bigint IS NULL OR t.to_account_id = $3))
+  )
+  AND (
+    $4::varchar IS NULL
+    OR
+    (fa.currency = $4
+      AND ($5::bigint IS NULL OR t.amount >= $5)
+      AND ($6::bigint IS NULL OR t.amount <= $6))
+    OR
+    (ta.currency = $4
+      AND ($5::bigint IS NULL OR t.to_amount >= $5)
+      AND ($6::bigint IS NULL OR t.to_amount <= $6))
+  )
+  AND ($7::timestamptz IS NULL OR t.created_at >= $7)
+  AND ($8::timestamptz IS NULL OR t.created_at < $8)
+  AND (t.created_at, t.id) > (
+    COALESCE($9::timestamptz, '-infinity'),
+    COALESCE($10::bigint, 0)
+  )
+ORDER BY t.created_at, t.id
+LIMIT $11
+`
+
+type ListUserTransfersOldestFirstParams struct {
+	Direction      pgtype.Text        `json:"direction"`
+	Owner          string             `json:"owner"`
+	AccountID      pgtype.Int8        `json:"account_id"`
+	Currency       pgtype.Text        `json:"currency"`
+	MinAmount      pgtype.Int8        `json:"min_amount"`
+	MaxAmount      pgtype.Int8        `json:"max_amount"`
+	FromTime       pgtype.Timestamptz `json:"from_time"`
+	ToTime         pgtype.Timestamptz `json:"to_time"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.Int8        `json:"after_id"`
+	RowLimit       int32              `json:"row_limit"`
+}
+
+type ListUserTransfersOldestFirstRow struct {
+	Transfer     Transfer `json:"transfer"`
+	FromCurrency string   `json:"from_currency"`
+	ToCurrency   string   `json:"to_currency"`
+}
+
+// Same filters as ListUserTransfersNewestFirst, paged the other way.
+// Without a cursor the page starts at the oldest transfer
+func (q *Queries) ListUserTransfersOldestFirst(ctx context.Context, arg ListUserTransfersOldestFirstParams) ([]ListUserTransfersOldestFirstRow, error) {
+	rows, err := q.db.Query(ctx, listUserTransfersOldestFirst,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.Currency,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserTransfersOldestFirstRow{}
+	for rows.Next() {
+		var i ListUserTransfersOldestFirstRow
+		if err := rows.Scan(
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.ToAmount,
+			&i.Transfer.ExchangeRate,
+			&i.Transfer.ReversesTransferID,
+			&i.Transfer.ReversalReason,
+			&i.Transfer.StandingOrderID,
+			&i.FromCurrency,
+			&i.ToCurrency,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,82 @@
+package db
+
+import "errors"
+
+const (
+	ReversalStatusNone    = "not_reversed"
+	ReversalStatusPartial = "partially_reversed"
+	ReversalStatusFull    = "reversed"
+)
+
+const (
+	ReversalReasonDuplicate           = "duplicate"
+	ReversalReasonFraudulent          = "fraudulent"
+	ReversalReasonRequestedByCustomer = "requested_by_customer"
+	ReversalReasonProcessingError     = "processing_error"
+)
+
+// ReversalReasons lists the reason codes a reversal can be given
+var ReversalReasons = []string{
+	ReversalReasonDuplicate,
+	ReversalReasonFraudulent,
+	ReversalReasonRequestedByCustomer,
+	ReversalReasonProcessingError,
+}
+
+// ErrTransferIsReversal is returned when reversing a transfer that is itself a reversal
+var ErrTransferIsReversal = errors.New("a reversal cannot be reversed")
+
+// ErrTransferReversed is returned when a transfer has already been reversed in full
+var ErrTransferReversed = errors.New("transfer is already fully reversed")
+
+// ErrReversalExceedsTransfer is returned when a reversal is larger than what is left to reverse
+var ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+
+// ErrReversalTooSmall is returned when a partial reversal converts to nothing in the original currency
+var ErrReversalTooSmall = errors.New("reversal amount is too small to convert back")
+
+// ReversalStatus is how much of a transfer has been paid back.
+// The amounts are in the currency of the to account, which pays the reversals
+type ReversalStatus struct {
+	Status          string `json:"status"`
+	ReversedAmount  int64  `json:"reversed_amount"`
+	RemainingAmount int64  `json:"remaining_amount"`
+}
+
+// IsReversalReason reports whether reason is one of ReversalReasons
+func IsReversalReason(reason string) bool {
+	for _, r := range ReversalReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// newReversalStatus describes a transfer of which reversedAmount has been reversed so far
+func newReversalStatus(transfer Transfer, reversedAmount int64) ReversalStatus {
+	status := ReversalStatus{
+		Status:          ReversalStatusPartial,
+		ReversedAmount:  reversedAmount,
+		RemainingAmount: transfer.ToAmount - reversedAmount,
+	}
+
+	switch {
+	case reversedAmount == 0:
+		status.Status = ReversalStatusNone
+	case status.RemainingAmount <= 0:
+		status.Status = ReversalStatusFull
+	}
+
+	return status
+}
+
+// ReversalStatus is how much of the transfer has been paid back, or nil if it is a reversal itself
+func (row GetTransferDetailsRow) ReversalStatus() *ReversalStatus {
+	if row.Transfer.ReversesTransferID.Valid {
+		return nil
+	}
+
+	status := newReversalStatus(row.Transfer, row.ReversedAmount)
+	return &status
+}
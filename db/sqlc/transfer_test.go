@@ -5,15 +5,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/drmanalo/simplebank/money"
 	"github.com/drmanalo/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 )
 
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	amount := util.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  money.OneRate,
 	}
 
 	transfer, err := testStore.CreateTransfer(context.Background(), arg)
@@ -23,6 +28,8 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	assert.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	assert.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	assert.Equal(t, arg.Amount, transfer.Amount)
+	assert.Equal(t, arg.ToAmount, transfer.ToAmount)
+	assert.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
 
 	assert.NotZero(t, transfer.ID)
 	assert.NotZero(t, transfer.CreatedAt)
@@ -62,10 +69,9 @@ func TestListTrans(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		RowLimit:  5,
+		RowOffset: 5,
 	}
 
 	transfers, err := testStore.ListTransfers(context.Background(), arg)
@@ -77,3 +83,114 @@ func TestListTrans(t *testing.T) {
 		assert.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
 }
+
+func TestGetTransferDetails(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	details, err := testStore.GetTransferDetails(context.Background(), transfer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, transfer, details.Transfer)
+	assert.Equal(t, account1.Owner, details.FromOwner)
+	assert.Equal(t, account1.Currency, details.FromCurrency)
+	assert.Equal(t, account2.Owner, details.ToOwner)
+	assert.Equal(t, account2.Currency, details.ToCurrency)
+}
+
+func TestListUserTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	createTransfer := func(from, to Account, amount int64) Transfer {
+		transfer, err := testStore.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+			ToAmount:      amount,
+			ExchangeRate:  money.OneRate,
+		})
+		assert.NoError(t, err)
+		return transfer
+	}
+
+	sent1 := createTransfer(account1, account2, 100)
+	sent2 := createTransfer(account1, account2, 200)
+	received := createTransfer(account2, account1, 300)
+
+	list := func(arg ListUserTransfersOldestFirstParams) []int64 {
+		if arg.RowLimit == 0 {
+			arg.RowLimit = 10
+		}
+		rows, err := testStore.ListUserTransfersOldestFirst(context.Background(), arg)
+		assert.NoError(t, err)
+
+		ids := []int64{}
+		for _, row := range rows {
+			ids = append(ids, row.Transfer.ID)
+		}
+		return ids
+	}
+
+	listNewestFirst := func(arg ListUserTransfersNewestFirstParams) []int64 {
+		if arg.RowLimit == 0 {
+			arg.RowLimit = 10
+		}
+		rows, err := testStore.ListUserTransfersNewestFirst(context.Background(), arg)
+		assert.NoError(t, err)
+
+		ids := []int64{}
+		for _, row := range rows {
+			ids = append(ids, row.Transfer.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{received.ID, sent2.ID, sent1.ID}, listNewestFirst(ListUserTransfersNewestFirstParams{
+		Owner: account1.Owner,
+	}))
+	assert.Equal(t, []int64{sent2.ID, sent1.ID}, listNewestFirst(ListUserTransfersNewestFirstParams{
+		Owner:          account1.Owner,
+		AfterCreatedAt: received.CreatedAt,
+		AfterID:        pgtype.Int8{Int64: received.ID, Valid: true},
+	}))
+
+	assert.Equal(t, []int64{received.ID}, list(ListUserTransfersOldestFirstParams{
+		Owner:     account1.Owner,
+		Direction: pgtype.Text{String: "incoming", Valid: true},
+	}))
+
+	assert.Equal(t, []int64{sent1.ID, sent2.ID}, list(ListUserTransfersOldestFirstParams{
+		Owner:     account2.Owner,
+		Direction: pgtype.Text{String: "incoming", Valid: true},
+		AccountID: pgtype.Int8{Int64: account2.ID, Valid: true},
+	}))
+
+	assert.Equal(t, []int64{sent2.ID}, list(ListUserTransfersOldestFirstParams{
+		Owner:     account1.Owner,
+		Direction: pgtype.Text{String: "outgoing", Valid: true},
+		Currency:  pgtype.Text{String: account1.Currency, Valid: true},
+		MinAmount: pgtype.Int8{Int64: 150, Valid: true},
+		MaxAmount: pgtype.Int8{Int64: 250, Valid: true},
+	}))
+
+	assert.Empty(t, list(ListUserTransfersOldestFirstParams{
+		Owner:    account1.Owner,
+		FromTime: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	}))
+
+	// a user with no part in the transfers sees none of them
+	assert.Empty(t, list(ListUserTransfersOldestFirstParams{
+		Owner: createRandomUser(t).Username,
+	}))
+
+	assert.Equal(t, []int64{sent1.ID, sent2.ID}, list(ListUserTransfersOldestFirstParams{
+		Owner:    account1.Owner,
+		RowLimit: 2,
+	}))
+	assert.Equal(t, []int64{received.ID}, list(ListUserTransfersOldestFirstParams{
+		Owner:          account1.Owner,
+		AfterCreatedAt: sent2.CreatedAt,
+		AfterID:        pgtype.Int8{Int64: sent2.ID, Valid: true},
+	}))
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeAccountStatusTxParams contains the input parameters of the change account status transaction
+type ChangeAccountStatusTxParams struct {
+	AccountID int64
+	Status    string
+	// SweepAccountID receives the remaining balance when the account is closed.
+	// It can be left out if the balance is already zero
+	SweepAccountID int64
+}
+
+// ChangeAccountStatusTxResult is the result of the change account status transaction
+type ChangeAccountStatusTxResult struct {
+	Account Account
+	// Sweep is the transfer of the remaining balance, or nil if closing the account didn't need one
+	Sweep *TransferTxResult
+}
+
+// ChangeAccountStatusTx moves the account to a new status within a database transaction.
+// Closing an account with money left in it transfers the balance to the sweep account first.
+// It returns ErrInvalidStatusChange if the account cannot move to the status
+// and ErrAccountNotEmpty if the balance stops it from being closed
+func (store *SLQStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accountIDs := []int64{arg.AccountID}
+		if arg.Status == AccountStatusClosed && arg.SweepAccountID != 0 {
+			accountIDs = append(accountIDs, arg.SweepAccountID)
+		}
+
+		accounts, err := lockAccounts(ctx, q, accountIDs...)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[arg.AccountID]
+		if !CanChangeAccountStatus(account.Status, arg.Status) {
+			return fmt.Errorf("%w: account [%d] is %s", ErrInvalidStatusChange, account.ID, account.Status)
+		}
+
+		if arg.Status == AccountStatusClosed && account.Balance != 0 {
+			// an overdrawn balance is owed to the bank, so there is nothing to sweep
+			if account.Balance < 0 || arg.SweepAccountID == 0 || arg.SweepAccountID == arg.AccountID {
+				return ErrAccountNotEmpty
+			}
+
+			sweep, err := transfer(ctx, q, TransferTxParams{
+				FromAccountID: arg.AccountID,
+				ToAccountID:   arg.SweepAccountID,
+				Amount:        account.Balance,
+			})
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     arg.AccountID,
+			Status: arg.Status,
+		})
+		return err
+	})
+
+	return result, err
+}
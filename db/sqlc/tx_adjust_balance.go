@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AdjustBalanceTxParams contains the input parameters of the adjust balance transaction
+type AdjustBalanceTxParams struct {
+	AccountID int64
+	// Amount is added to the balance, so a negative amount debits the account
+	Amount int64
+	Reason string
+}
+
+// AdjustBalanceTxResult is the result of the adjust balance transaction
+type AdjustBalanceTxResult struct {
+	Adjustment Adjustment `json:"adjustment"`
+	Account    Account    `json:"account"`
+	Entry      Entry      `json:"entry"`
+}
+
+// AdjustBalanceTx corrects the account balance outside of a transfer.
+// It records the adjustment with its reason and an entry for it, so the ledger still balances.
+// It returns an AccountStatusError if the account is closed and ErrInsufficientFunds
+// if a debit would take it past its overdraft limit
+func (store *SLQStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error) {
+	var result AdjustBalanceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// frozen accounts can still be corrected, but closed ones must stay empty
+		if account.Status == AccountStatusClosed {
+			return &AccountStatusError{AccountID: account.ID, Status: account.Status}
+		}
+
+		result.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+			Reason:    arg.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:    arg.AccountID,
+			Amount:       arg.Amount,
+			AdjustmentID: pgtype.Int8{Int64: result.Adjustment.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = applyBalanceChange(ctx, q, arg.AccountID, arg.Amount)
+		return err
+	})
+
+	return result, err
+}
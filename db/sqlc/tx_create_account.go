@@ -0,0 +1,32 @@
+package db
+
+import "context"
+
+// CreateAccountTxParams contains the input parameters of the create account transaction
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+// CreateAccountTxResult is the result of the create account transaction
+type CreateAccountTxResult struct {
+	Account Account
+}
+
+// CreateAccountTx creates a new account and saves the optional idempotency key within the same database transaction
+func (store *SLQStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result.Account)
+	})
+
+	return result, err
+}
@@ -0,0 +1,49 @@
+package db
+
+import "context"
+
+// CreateUserTxParams contains the input parameters of the create user transaction
+type CreateUserTxParams struct {
+	CreateUserParams
+	// Role replaces the default depositor role when it is set
+	Role        string
+	AfterCreate func(user User) error
+}
+
+// CreateUserTxResult is the result of the create user transaction
+type CreateUserTxResult struct {
+	User User
+}
+
+// CreateUserTx creates a new user and runs the AfterCreate callback within the same database transaction.
+// If the callback returns an error, the user is rolled back
+func (store *SLQStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.Role != "" {
+			result.User, err = q.UpdateUserRole(ctx, UpdateUserRoleParams{
+				Username: result.User.Username,
+				Role:     arg.Role,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.AfterCreate == nil {
+			return nil
+		}
+
+		return arg.AfterCreate(result.User)
+	})
+
+	return result, err
+}
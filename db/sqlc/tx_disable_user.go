@@ -0,0 +1,34 @@
+package db
+
+import "context"
+
+// DisableUserTxParams contains the input parameters of the disable user transaction
+type DisableUserTxParams struct {
+	Username string
+}
+
+// DisableUserTxResult is the result of the disable user transaction
+type DisableUserTxResult struct {
+	User            User
+	BlockedSessions int64
+}
+
+// DisableUserTx stops the user from logging in and blocks their sessions within a database transaction.
+// Access tokens that were already issued stay valid until they expire
+func (store *SLQStore) DisableUserTx(ctx context.Context, arg DisableUserTxParams) (DisableUserTxResult, error) {
+	var result DisableUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.DisableUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, arg.Username)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StatementBalances are the balances of an account at the start and end of a statement period
+type StatementBalances struct {
+	OpeningBalance int64
+	ClosingBalance int64
+}
+
+// ExportStatementTxParams contains the input parameters of the export statement transaction.
+// Write is called once with the balances and streams the lines through the function it is given
+type ExportStatementTxParams struct {
+	AccountID int64
+	FromTime  pgtype.Timestamptz
+	ToTime    pgtype.Timestamptz
+	Write     func(balances StatementBalances, lines func(fn func(ListStatementLinesRow) error) error) error
+}
+
+// ExportStatementTx reads the balances of a statement period and streams its lines from a single
+// REPEATABLE READ READ ONLY snapshot, so transfers made during the export cannot make them disagree
+func (store *SLQStore) ExportStatementTx(ctx context.Context, arg ExportStatementTxParams) error {
+	opts := pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	}
+
+	return store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var balances StatementBalances
+		var err error
+
+		balances.OpeningBalance, err = q.GetStatementOpeningBalance(ctx, GetStatementOpeningBalanceParams{
+			FromTime:  arg.FromTime,
+			AccountID: arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		balances.ClosingBalance, err = q.GetStatementOpeningBalance(ctx, GetStatementOpeningBalanceParams{
+			FromTime:  arg.ToTime,
+			AccountID: arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return arg.Write(balances, func(fn func(ListStatementLinesRow) error) error {
+			return q.StreamStatementLines(ctx, ListStatementLinesParams{
+				AccountID: arg.AccountID,
+				FromTime:  arg.FromTime,
+				ToTime:    arg.ToTime,
+			}, fn)
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is in the currency of the original to account, which pays it back.
+	// Zero reverses whatever is left of the transfer
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// IdempotencyKey is saved with the result so a retried request can be replayed
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction
+type ReverseTransferTxResult struct {
+	// Reversal moves the money from the original to account back to the original from account
+	Reversal TransferTxResult `json:"reversal"`
+	Original Transfer         `json:"original"`
+	// OriginalStatus is the reversal status of the original transfer, including this reversal
+	OriginalStatus ReversalStatus `json:"original_status"`
+}
+
+// ReverseTransferTx pays a transfer back, in full or in part, with an opposite transfer linked to it.
+// The original transfer is locked, so concurrent partial reversals can never add up to more than it.
+// A partial reversal of a cross-currency transfer is converted back at the original rate,
+// and the last one credits whatever is left of the original amount so nothing is lost to rounding.
+// Besides the errors of TransferTx, it returns ErrTransferIsReversal, ErrTransferReversed,
+// ErrReversalExceedsTransfer and ErrReversalTooSmall
+func (store *SLQStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Original, err = q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if result.Original.ReversesTransferID.Valid {
+			return ErrTransferIsReversal
+		}
+
+		reversed, err := q.GetTransferReversedAmounts(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		remaining := result.Original.ToAmount - reversed.ReversedAmount
+		if remaining <= 0 {
+			return ErrTransferReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		toAmount := result.Original.Amount - reversed.ReversedToAmount
+		if amount < remaining {
+			toAmount = convertBack(result.Original, amount)
+		}
+		if toAmount <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		result.Reversal, err = moveMoney(ctx, q, CreateTransferParams{
+			FromAccountID:      result.Original.ToAccountID,
+			ToAccountID:        result.Original.FromAccountID,
+			Amount:             amount,
+			ToAmount:           toAmount,
+			ExchangeRate:       result.Original.ExchangeRate.Inverse(),
+			ReversesTransferID: pgtype.Int8{Int64: result.Original.ID, Valid: true},
+			ReversalReason:     pgtype.Text{String: arg.Reason, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.OriginalStatus = newReversalStatus(result.Original, reversed.ReversedAmount+amount)
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
+
+	return result, err
+}
+
+// convertBack converts part of the to amount of a transfer back to its from currency,
+// in the same proportion as the transfer so the rate it was made at is kept. It rounds down
+func convertBack(transfer Transfer, amount int64) int64 {
+	n := new(big.Int).Mul(big.NewInt(amount), big.NewInt(transfer.Amount))
+	return n.Quo(n, big.NewInt(transfer.ToAmount)).Int64()
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateScheduledTransferTxParams contains the input parameters of the create scheduled transfer transaction
+type CreateScheduledTransferTxParams struct {
+	CreateScheduledTransferParams
+	// IdempotencyKey is saved with the result so a retried request can be replayed
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+// CreateScheduledTransferTx schedules a transfer and saves the idempotency key of the request with it
+func (store *SLQStore) CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (ScheduledTransfer, error) {
+	var result ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateScheduledTransfer(ctx, arg.CreateScheduledTransferParams)
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
+
+	return result, err
+}
+
+// ExecuteScheduledTransferTxParams contains the input parameters of the execute scheduled transfer transaction
+type ExecuteScheduledTransferTxParams struct {
+	// Now is compared with next_attempt_at to find the transfers that are due, and retries are scheduled from it
+	Now time.Time
+	// Convert is called for transfers between accounts of different currencies
+	Convert ConvertFunc
+	// Retry spaces out the attempts at a transfer that failed for an unexpected reason.
+	// Its backoff is also how long a claimed transfer is left alone while it runs
+	Retry RetryPolicy
+}
+
+// ExecuteScheduledTransferTxResult is the result of the execute scheduled transfer transaction
+type ExecuteScheduledTransferTxResult struct {
+	// ScheduledTransfer is set whenever a transfer was claimed, even if an error is returned.
+	// It is still pending, with LastError set, when the attempt is to be retried
+	ScheduledTransfer ScheduledTransfer `json:"scheduled_transfer"`
+	// Transfer is nil unless the scheduled transfer was executed
+	Transfer *TransferTxResult `json:"transfer"`
+}
+
+// ExecuteScheduledTransferTx claims the next due scheduled transfer and runs it like TransferTx.
+// The claim is committed on its own, so the exchange rate of a cross-currency transfer is looked up
+// without holding any lock. The transfer and its new status are then committed together,
+// and only while it is still pending, so it is never executed twice, even with several schedulers running.
+// Insufficient funds, an account that is not active or an amount that cannot be converted
+// mark it as failed with the reason. Any other error is recorded and the transfer is retried
+// as the policy says, then failed once it runs out of retries. It returns ErrRecordNotFound when nothing is due
+func (store *SLQStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	claimed, err := store.ClaimDueScheduledTransfer(ctx, ClaimDueScheduledTransferParams{
+		Now:        arg.Now,
+		LeaseUntil: arg.Now.Add(arg.Retry.delay(1)),
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ScheduledTransfer = claimed
+
+	// stepErr is why the transfer could not be made, starting with the conversion
+	transferArg, stepErr := store.convertTransferParams(ctx, CreateTransferParams{
+		FromAccountID: claimed.FromAccountID,
+		ToAccountID:   claimed.ToAccountID,
+		Amount:        claimed.Amount,
+	}, arg.Convert)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, claimed.ID)
+		if err != nil {
+			return err
+		}
+
+		// cancelled, or finished by another scheduler after the lease ran out
+		if scheduled.Status != ScheduledTransferPending {
+			result.ScheduledTransfer = scheduled
+			return nil
+		}
+
+		var transferResult TransferTxResult
+		if stepErr == nil {
+			stepErr = withSavepoint(ctx, q, func() error {
+				transferResult, err = moveMoney(ctx, q, transferArg)
+				return err
+			})
+		}
+
+		finish := FinishScheduledTransferParams{
+			ID:     scheduled.ID,
+			Status: ScheduledTransferExecuted,
+		}
+
+		switch {
+		case stepErr == nil:
+			result.Transfer = &transferResult
+			finish.TransferID = pgtype.Int8{Int64: transferResult.Transfer.ID, Valid: true}
+		case isTransferFailure(stepErr), scheduled.Attempts > arg.Retry.MaxRetries:
+			finish.Status = ScheduledTransferFailed
+			finish.FailureReason = pgtype.Text{String: stepErr.Error(), Valid: true}
+		default:
+			result.ScheduledTransfer, err = q.RetryScheduledTransfer(ctx, RetryScheduledTransferParams{
+				ID:            scheduled.ID,
+				LastError:     pgtype.Text{String: stepErr.Error(), Valid: true},
+				NextAttemptAt: arg.Now.Add(arg.Retry.delay(scheduled.Attempts)),
+			})
+			return err
+		}
+
+		result.ScheduledTransfer, err = q.FinishScheduledTransfer(ctx, finish)
+		return err
+	})
+
+	return result, err
+}
+
+// withSavepoint runs fn in a savepoint of the running transaction and rolls back to it if fn fails,
+// so the transaction can go on to record the failure
+func withSavepoint(ctx context.Context, q *Queries, fn func() error) error {
+	if _, err := q.db.Exec(ctx, "SAVEPOINT before_step"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := q.db.Exec(ctx, "ROLLBACK TO SAVEPOINT before_step"); rbErr != nil {
+			return fmt.Errorf("step err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := q.db.Exec(ctx, "RELEASE SAVEPOINT before_step")
+	return err
+}
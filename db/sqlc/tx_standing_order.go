@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateStandingOrderTxParams contains the input parameters of the create standing order transaction
+type CreateStandingOrderTxParams struct {
+	CreateStandingOrderParams
+	// IdempotencyKey is saved with the result so a retried request can be replayed
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+// CreateStandingOrderTx creates a standing order and saves the idempotency key of the request with it.
+// Its first occurrence is due at StartAt
+func (store *SLQStore) CreateStandingOrderTx(ctx context.Context, arg CreateStandingOrderTxParams) (StandingOrder, error) {
+	var result StandingOrder
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result, err = q.CreateStandingOrder(ctx, arg.CreateStandingOrderParams)
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
+
+	return result, err
+}
+
+// UpdateStandingOrderTxParams contains the input parameters of the update standing order transaction.
+// Fields that are not set are left unchanged
+type UpdateStandingOrderTxParams struct {
+	ID             int64              `json:"id"`
+	Amount         pgtype.Int8        `json:"amount"`
+	EndAt          pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences pgtype.Int4        `json:"max_occurrences"`
+}
+
+// UpdateStandingOrderTx changes the amount or the limits of an active standing order.
+// An order whose new limits leave no occurrence to pay completes straight away.
+// It returns ErrStandingOrderNotActive if the order has completed or been cancelled
+func (store *SLQStore) UpdateStandingOrderTx(ctx context.Context, arg UpdateStandingOrderTxParams) (StandingOrder, error) {
+	var result StandingOrder
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		order, err := q.GetStandingOrderForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != StandingOrderActive {
+			return ErrStandingOrderNotActive
+		}
+
+		if arg.Amount.Valid {
+			order.Amount = arg.Amount.Int64
+		}
+		if arg.EndAt.Valid {
+			order.EndAt = arg.EndAt
+		}
+		if arg.MaxOccurrences.Valid {
+			order.MaxOccurrences = arg.MaxOccurrences
+		}
+
+		// a pending retry keeps its time, unless the occurrence it retries is no longer wanted
+		_, ok, err := nextOccurrence(order)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			order.Status = StandingOrderCompleted
+			order.NextRunAt = pgtype.Timestamptz{}
+		}
+
+		result, err = saveStandingOrder(ctx, q, order)
+		return err
+	})
+
+	return result, err
+}
+
+// ExecuteStandingOrderTxParams contains the input parameters of the execute standing order transaction
+type ExecuteStandingOrderTxParams struct {
+	// Now is compared with next_run_at to find the orders that are due, and retries are scheduled from it
+	Now time.Time
+	// Convert is called for orders between accounts of different currencies
+	Convert ConvertFunc
+	// Retry spaces out the attempts at a failed occurrence.
+	// Its backoff is also how long a claimed order is left alone while it runs
+	Retry RetryPolicy
+}
+
+// ExecuteStandingOrderTxResult is the result of the execute standing order transaction
+type ExecuteStandingOrderTxResult struct {
+	// StandingOrder is set whenever an order was claimed, even if an error is returned.
+	// It has LastError set when this occurrence failed
+	StandingOrder StandingOrder `json:"standing_order"`
+	// Transfer is nil when the occurrence failed, or when the order changed while it was claimed
+	Transfer *TransferTxResult `json:"transfer"`
+}
+
+// ExecuteStandingOrderTx claims the next due standing order and pays its current occurrence
+// with a transfer linked back to the order, then moves the order on to its next occurrence.
+// The claim is committed on its own, so the exchange rate is looked up without holding any lock.
+// The payment and the next occurrence are then committed together, and only while the order
+// still holds this claim, so an occurrence is never paid twice.
+// An occurrence that fails for any reason is retried as the policy says, and skipped once
+// it runs out of retries. It returns ErrRecordNotFound when nothing is due
+func (store *SLQStore) ExecuteStandingOrderTx(ctx context.Context, arg ExecuteStandingOrderTxParams) (ExecuteStandingOrderTxResult, error) {
+	var result ExecuteStandingOrderTxResult
+
+	claimed, err := store.ClaimDueStandingOrder(ctx, ClaimDueStandingOrderParams{
+		Now:        pgtype.Timestamptz{Time: arg.Now, Valid: true},
+		LeaseUntil: pgtype.Timestamptz{Time: arg.Now.Add(arg.Retry.delay(1)), Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+	result.StandingOrder = claimed
+
+	// stepErr is why the occurrence could not be paid, starting with the conversion
+	transferArg, stepErr := store.convertTransferParams(ctx, CreateTransferParams{
+		FromAccountID:   claimed.FromAccountID,
+		ToAccountID:     claimed.ToAccountID,
+		Amount:          claimed.Amount,
+		StandingOrderID: pgtype.Int8{Int64: claimed.ID, Valid: true},
+	}, arg.Convert)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		order, err := q.GetStandingOrderForUpdate(ctx, claimed.ID)
+		if err != nil {
+			return err
+		}
+
+		// cancelled, changed or paid by another worker after the lease ran out
+		if !stillClaimed(order, claimed) {
+			result.StandingOrder = order
+			return nil
+		}
+
+		var transferResult TransferTxResult
+		if stepErr == nil {
+			stepErr = withSavepoint(ctx, q, func() error {
+				transferResult, err = moveMoney(ctx, q, transferArg)
+				return err
+			})
+		}
+
+		switch {
+		case stepErr == nil:
+			result.Transfer = &transferResult
+			order.Occurrences++
+			order.RetryCount = 0
+			order.LastError = pgtype.Text{}
+			if err := scheduleNextOccurrence(&order); err != nil {
+				return err
+			}
+		case order.RetryCount < arg.Retry.MaxRetries:
+			order.LastError = pgtype.Text{String: stepErr.Error(), Valid: true}
+			order.RetryCount++
+			order.NextRunAt = pgtype.Timestamptz{Time: arg.Now.Add(arg.Retry.delay(order.RetryCount)), Valid: true}
+		default:
+			// give up on this occurrence and wait for the next one.
+			// It is not paid, so it doesn't count towards MaxOccurrences
+			order.LastError = pgtype.Text{String: stepErr.Error(), Valid: true}
+			order.SkippedOccurrences++
+			order.RetryCount = 0
+			if err := scheduleNextOccurrence(&order); err != nil {
+				return err
+			}
+		}
+
+		result.StandingOrder, err = saveStandingOrder(ctx, q, order)
+		return err
+	})
+
+	return result, err
+}
+
+// stillClaimed reports whether the locked order is the one that was claimed, with the lease it was claimed with
+// and the amount the transfer was worked out for
+func stillClaimed(order StandingOrder, claimed StandingOrder) bool {
+	return order.Status == StandingOrderActive &&
+		order.NextRunAt.Valid &&
+		order.NextRunAt.Time.Equal(claimed.NextRunAt.Time) &&
+		order.Occurrences == claimed.Occurrences &&
+		order.SkippedOccurrences == claimed.SkippedOccurrences &&
+		order.Amount == claimed.Amount
+}
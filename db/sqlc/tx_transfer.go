@@ -1,12 +1,25 @@
 package db
 
-import "context"
+import (
+	"context"
+	"errors"
+	"github.com/drmanalo/simplebank/money"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// ToAmount and ExchangeRate are only set for cross-currency transfers.
+	// When ToAmount is zero the to account is credited with Amount at a rate of 1
+	ToAmount     int64      `json:"to_amount"`
+	ExchangeRate money.Rate `json:"exchange_rate"`
+	// IdempotencyKey is saved with the result so a retried request can be replayed
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -16,51 +29,147 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// ReversalStatus is how much of the transfer has been paid back.
+	// It is nil for reversals, as they cannot be reversed themselves
+	ReversalStatus *ReversalStatus `json:"reversal_status,omitempty"`
 }
 
 // TransferTx performs a money transfer from one account to the other.
-// It creates the transfer, add account entries, and update accounts' balance within a database transaction
+// It creates the transfer, add account entries, and update accounts' balance within a database transaction.
+// It returns ErrInsufficientFunds if the from account would go past its overdraft limit
+// and an AccountStatusError if either account is not active
 func (store *SLQStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		result, err = transfer(ctx, q, arg)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+		return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	})
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
+
+// transfer moves the money with q, so it can be part of a larger transaction
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	toAmount, exchangeRate := arg.ToAmount, arg.ExchangeRate
+	if toAmount == 0 {
+		toAmount, exchangeRate = arg.Amount, money.OneRate
+	}
+
+	return moveMoney(ctx, q, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  exchangeRate,
+	})
+}
+
+// convertTransferParams fills in the amount the to account receives, converting it with convert
+// when the accounts hold different currencies. It runs outside of any transaction
+func (store *SLQStore) convertTransferParams(ctx context.Context, arg CreateTransferParams, convert ConvertFunc) (CreateTransferParams, error) {
+	fromAccount, err := store.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return arg, err
+	}
+
+	toAccount, err := store.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return arg, err
+	}
+
+	arg.ToAmount, arg.ExchangeRate = arg.Amount, money.OneRate
+	if fromAccount.Currency != toAccount.Currency {
+		arg.ToAmount, arg.ExchangeRate, err = convert(ctx, arg.Amount, fromAccount.Currency, toAccount.Currency)
+	}
+
+	return arg, err
+}
+
+// moveMoney creates the transfer with its entries and updates both balances.
+// It returns an AccountStatusError if either account is not active
+func moveMoney(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	accounts, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	for _, accountID := range []int64{arg.FromAccountID, arg.ToAccountID} {
+		if status := accounts[accountID].Status; status != AccountStatusActive {
+			return result, &AccountStatusError{AccountID: accountID, Status: status}
 		}
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
 
-		return err
+	transferID := pgtype.Int8{Int64: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: transferID,
 	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.ToAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.Amount)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if !arg.ReversesTransferID.Valid {
+		status := newReversalStatus(result.Transfer, 0)
+		result.ReversalStatus = &status
+	}
+
+	return result, nil
+}
+
+// lockAccounts locks the accounts for update in ID order,
+// so transactions locking the same accounts wait for each other instead of deadlocking
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	ids := append([]int64(nil), accountIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if _, ok := accounts[id]; ok {
+			continue
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
 }
 
 func addMoney(
@@ -71,17 +180,25 @@ func addMoney(
 	accountID2 int64,
 	amount2 int64,
 ) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
+	account1, err = applyBalanceChange(ctx, q, accountID1, amount1)
 	if err != nil {
 		return
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
-	})
+	account2, err = applyBalanceChange(ctx, q, accountID2, amount2)
 	return
 }
+
+// applyBalanceChange adds amount to the account balance.
+// The update matches no row when a debit would exceed the overdraft limit
+func applyBalanceChange(ctx context.Context, q *Queries, accountID int64, amount int64) (Account, error) {
+	account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID,
+		Amount: amount,
+	})
+	if errors.Is(err, ErrRecordNotFound) {
+		return account, ErrInsufficientFunds
+	}
+
+	return account, err
+}
@@ -0,0 +1,38 @@
+package db
+
+import "context"
+
+// VerifyEmailTxParams contains the input parameters of the verify email transaction
+type VerifyEmailTxParams struct {
+	EmailID    int64
+	SecretCode string
+}
+
+// VerifyEmailTxResult is the result of the verify email transaction
+type VerifyEmailTxResult struct {
+	User        User
+	VerifyEmail VerifyEmail
+}
+
+// VerifyEmailTx marks the verify email record as used and flags the user's email as verified
+// within a database transaction
+func (store *SLQStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.VerifyEmail, err = q.UpdateVerifyEmail(ctx, UpdateVerifyEmailParams{
+			ID:         arg.EmailID,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.VerifyUserEmail(ctx, result.VerifyEmail.Username)
+		return err
+	})
+
+	return result, err
+}
@@ -17,7 +17,7 @@ INSERT INTO users (
   username
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, created_at
+) RETURNING username, hashed_password, full_name, email, created_at, is_email_verified, role, is_disabled
 `
 
 type CreateUserParams struct {
@@ -41,12 +41,38 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.FullName,
 		&i.Email,
 		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+	)
+	return i, err
+}
+
+const disableUser = `-- name: DisableUser :one
+UPDATE users
+SET is_disabled = true
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, created_at, is_email_verified, role, is_disabled
+`
+
+func (q *Queries) DisableUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, disableUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, created_at FROM users
+SELECT username, hashed_password, full_name, email, created_at, is_email_verified, role, is_disabled FROM users
 WHERE username = $1
 `
 
@@ -59,6 +85,60 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.FullName,
 		&i.Email,
 		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, created_at, is_email_verified, role, is_disabled
+`
+
+type UpdateUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, created_at, is_email_verified, role, is_disabled
+`
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.IsDisabled,
 	)
 	return i, err
 }
@@ -9,16 +9,20 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func createRandomUser(t *testing.T) User {
+func randomCreateUserParams(t *testing.T) CreateUserParams {
 	hashedPassword, err := util.HashPassword(util.RandomString(6))
 	assert.NoError(t, err)
 
-	arg := CreateUserParams{
+	return CreateUserParams{
 		Email:          util.RandomEmail(),
 		FullName:       util.RandomOwner(),
 		HashedPassword: hashedPassword,
 		Username:       util.RandomOwner(),
 	}
+}
+
+func createRandomUser(t *testing.T) User {
+	arg := randomCreateUserParams(t)
 
 	user, err := testStore.CreateUser(context.Background(), arg)
 	assert.NoError(t, err)
@@ -28,6 +32,7 @@ func createRandomUser(t *testing.T) User {
 	assert.Equal(t, arg.FullName, user.FullName)
 	assert.Equal(t, arg.HashedPassword, user.HashedPassword)
 	assert.Equal(t, arg.Username, user.Username)
+	assert.Equal(t, util.DepositorRole, user.Role)
 
 	assert.NotZero(t, user.CreatedAt)
 
@@ -50,3 +55,25 @@ func TestGetUser(t *testing.T) {
 	assert.Equal(t, user1.Username, user2.Username)
 	assert.WithinDuration(t, user1.CreatedAt.Time, user2.CreatedAt.Time, time.Second)
 }
+
+func TestCreateUserTxRole(t *testing.T) {
+	result, err := testStore.CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: randomCreateUserParams(t),
+		Role:             util.BankerRole,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, util.BankerRole, result.User.Role)
+}
+
+func TestDisableUserTx(t *testing.T) {
+	session := createRandomSession(t)
+
+	result, err := testStore.DisableUserTx(context.Background(), DisableUserTxParams{Username: session.Username})
+	assert.NoError(t, err)
+	assert.True(t, result.User.IsDisabled)
+	assert.Equal(t, int64(1), result.BlockedSessions)
+
+	sessions, err := testStore.ListActiveSessions(context.Background(), session.Username)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRandomVerifyEmail(t *testing.T) VerifyEmail {
+	user := createRandomUser(t)
+
+	arg := CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	}
+
+	verifyEmail, err := testStore.CreateVerifyEmail(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, verifyEmail)
+
+	assert.Equal(t, arg.Username, verifyEmail.Username)
+	assert.Equal(t, arg.Email, verifyEmail.Email)
+	assert.Equal(t, arg.SecretCode, verifyEmail.SecretCode)
+	assert.False(t, verifyEmail.IsUsed)
+	assert.True(t, verifyEmail.ExpiredAt.After(verifyEmail.CreatedAt))
+
+	return verifyEmail
+}
+
+func TestCreateVerifyEmail(t *testing.T) {
+	createRandomVerifyEmail(t)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	verifyEmail := createRandomVerifyEmail(t)
+
+	result, err := testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.VerifyEmail.IsUsed)
+	assert.True(t, result.User.IsEmailVerified)
+	assert.Equal(t, verifyEmail.Username, result.User.Username)
+
+	// the secret code can only be used once
+	_, err = testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestVerifyEmailTxWrongSecretCode(t *testing.T) {
+	verifyEmail := createRandomVerifyEmail(t)
+
+	_, err := testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: util.RandomString(32),
+	})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	user, err := testStore.GetUser(context.Background(), verifyEmail.Username)
+	assert.NoError(t, err)
+	assert.False(t, user.IsEmailVerified)
+}
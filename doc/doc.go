@@ -0,0 +1,18 @@
+package doc
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed swagger
+var swaggerFS embed.FS
+
+// SwaggerFS returns the embedded Swagger UI and the OpenAPI document generated from the protos
+func SwaggerFS() fs.FS {
+	swagger, err := fs.Sub(swaggerFS, "swagger")
+	if err != nil {
+		panic(err)
+	}
+	return swagger
+}
@@ -0,0 +1,30 @@
+package doc
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwaggerFS(t *testing.T) {
+	swagger := SwaggerFS()
+
+	_, err := fs.Stat(swagger, "index.html")
+	assert.NoError(t, err)
+
+	data, err := fs.ReadFile(swagger, "simple_bank.swagger.json")
+	assert.NoError(t, err)
+
+	var spec struct {
+		Swagger string                     `json:"swagger"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	err = json.Unmarshal(data, &spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0", spec.Swagger)
+	assert.Contains(t, spec.Paths, "/v1/users")
+	assert.Contains(t, spec.Paths, "/v1/accounts/{id}")
+	assert.Contains(t, spec.Paths, "/v1/transfers")
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// writeCAMT053 writes a camt.053.001.02 document holding a single statement.
+// Both balances come before the entries in the schema, which is why Statement carries the closing balance up front
+func writeCAMT053(w io.Writer, statement Statement, lines Lines) error {
+	id := fmt.Sprintf("STMT-%d-%s-%s",
+		statement.AccountID,
+		statement.From.UTC().Format("20060102"),
+		statement.To.UTC().Format("20060102"),
+	)
+
+	x := newXMLWriter(w, `<?xml version="1.0" encoding="UTF-8"?>`)
+	x.start("Document", xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace})
+	x.start("BkToCstmrStmt")
+
+	x.start("GrpHdr")
+	x.leaf("MsgId", id)
+	x.leaf("CreDtTm", formatISOTime(statement.GeneratedAt))
+	x.end("GrpHdr")
+
+	x.start("Stmt")
+	x.leaf("Id", id)
+	x.leaf("CreDtTm", formatISOTime(statement.GeneratedAt))
+	x.start("FrToDt")
+	x.leaf("FrDtTm", formatISOTime(statement.From))
+	x.leaf("ToDtTm", formatISOTime(statement.To))
+	x.end("FrToDt")
+
+	x.start("Acct")
+	writeCAMT053AccountID(x, statement.AccountID)
+	x.leaf("Ccy", statement.Currency)
+	x.start("Ownr")
+	x.leaf("Nm", statement.Owner)
+	x.end("Ownr")
+	x.end("Acct")
+
+	writeCAMT053Balance(x, "OPBD", statement.OpeningBalance, statement.From)
+	writeCAMT053Balance(x, "CLBD", statement.ClosingBalance, statement.To)
+
+	err := lines(func(line Line) error {
+		writeCAMT053Entry(x, statement, line)
+		return x.err
+	})
+	if err != nil {
+		return err
+	}
+
+	x.end("Stmt")
+	x.end("BkToCstmrStmt")
+	x.end("Document")
+	return x.flush()
+}
+
+func writeCAMT053AccountID(x *xmlWriter, accountID int64) {
+	x.start("Id")
+	x.start("Othr")
+	x.leaf("Id", strconv.FormatInt(accountID, 10))
+	x.end("Othr")
+	x.end("Id")
+}
+
+func writeCAMT053Balance(x *xmlWriter, code string, amount money.Amount, at time.Time) {
+	x.start("Bal")
+	x.start("Tp")
+	x.start("CdOrPrtry")
+	x.leaf("Cd", code)
+	x.end("CdOrPrtry")
+	x.end("Tp")
+	writeCAMT053Amount(x, amount)
+	x.start("Dt")
+	x.leaf("DtTm", formatISOTime(at))
+	x.end("Dt")
+	x.end("Bal")
+}
+
+func writeCAMT053Entry(x *xmlWriter, statement Statement, line Line) {
+	x.start("Ntry")
+	x.leaf("NtryRef", strconv.FormatInt(line.EntryID, 10))
+	writeCAMT053Amount(x, line.Amount)
+	x.leaf("Sts", "BOOK")
+	x.start("BookgDt")
+	x.leaf("DtTm", formatISOTime(line.CreatedAt))
+	x.end("BookgDt")
+
+	code := "ENTRY"
+	if line.TransferID != 0 {
+		code = "TRANSFER"
+	}
+	x.start("BkTxCd")
+	x.start("Prtry")
+	x.leaf("Cd", code)
+	x.end("Prtry")
+	x.end("BkTxCd")
+
+	if line.TransferID != 0 {
+		x.start("NtryDtls")
+		x.start("TxDtls")
+		x.start("Refs")
+		x.leaf("TxId", strconv.FormatInt(line.TransferID, 10))
+		x.end("Refs")
+
+		// money coming in was sent by the counterparty, money going out was received by them
+		debtor, debtorAccount := line.CounterpartyOwner, line.CounterpartyAccountID
+		creditor, creditorAccount := statement.Owner, statement.AccountID
+		if line.Amount.Minor < 0 {
+			debtor, creditor = creditor, debtor
+			debtorAccount, creditorAccount = creditorAccount, debtorAccount
+		}
+		x.start("RltdPties")
+		writeCAMT053Party(x, "Dbtr", debtor, debtorAccount)
+		writeCAMT053Party(x, "Cdtr", creditor, creditorAccount)
+		x.end("RltdPties")
+
+		x.end("TxDtls")
+		x.end("NtryDtls")
+	}
+
+	x.end("Ntry")
+}
+
+// writeCAMT053Party writes a party followed by its account, e.g. Dbtr then DbtrAcct
+func writeCAMT053Party(x *xmlWriter, role string, name string, accountID int64) {
+	x.start(role)
+	x.leaf("Nm", name)
+	x.end(role)
+	x.start(role + "Acct")
+	writeCAMT053AccountID(x, accountID)
+	x.end(role + "Acct")
+}
+
+// writeCAMT053Amount writes the unsigned amount with its currency, then whether it is a credit or a debit
+func writeCAMT053Amount(x *xmlWriter, amount money.Amount) {
+	indicator := "CRDT"
+	if amount.Minor < 0 {
+		indicator = "DBIT"
+	}
+	x.leaf("Amt", strings.TrimPrefix(amount.String(), "-"), xml.Attr{Name: xml.Name{Local: "Ccy"}, Value: amount.Currency})
+	x.leaf("CdtDbtInd", indicator)
+}
+
+// formatISOTime formats t as an ISO 8601 datetime in UTC
+func formatISOTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"date",
+	"entry_id",
+	"transfer_id",
+	"counterparty_account_id",
+	"counterparty_owner",
+	"amount",
+	"running_balance",
+	"currency",
+}
+
+func writeCSV(w io.Writer, lines Lines) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	err := lines(func(line Line) error {
+		return writer.Write([]string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.EntryID, 10),
+			formatOptionalID(line.TransferID),
+			formatOptionalID(line.CounterpartyAccountID),
+			line.CounterpartyOwner,
+			line.Amount.String(),
+			line.RunningBalance.String(),
+			line.Amount.Currency,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatOptionalID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
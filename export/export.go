@@ -0,0 +1,78 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Format is a statement file format, named after its file extension
+type Format string
+
+const (
+	CSV Format = "csv"
+	// OFX is Open Financial Exchange 2.x, read by personal finance software
+	OFX Format = "ofx"
+	// CAMT053 is the ISO 20022 bank to customer statement, read by corporate ERPs
+	CAMT053 Format = "xml"
+)
+
+// ContentType returns the MIME type to serve the format with
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case OFX:
+		return "application/x-ofx"
+	case CAMT053:
+		return "application/xml; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// Statement describes the account and period being exported
+type Statement struct {
+	AccountID      int64
+	Owner          string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance money.Amount
+	ClosingBalance money.Amount
+	// GeneratedAt is when the file was produced, written into the OFX and camt.053 headers
+	GeneratedAt time.Time
+}
+
+// Line is a single entry on the statement.
+// TransferID and CounterpartyAccountID are zero for entries that did not come from a transfer
+type Line struct {
+	EntryID               int64
+	Amount                money.Amount
+	RunningBalance        money.Amount
+	TransferID            int64
+	CounterpartyAccountID int64
+	CounterpartyOwner     string
+	CreatedAt             time.Time
+}
+
+// Lines calls fn for each line of the statement in order, stopping at the first error
+type Lines func(fn func(Line) error) error
+
+// Write renders the statement to w in the given format.
+// Lines are pulled and written one at a time so the period can be arbitrarily long
+func Write(w io.Writer, format Format, statement Statement, lines Lines) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, lines)
+	case OFX:
+		return writeOFX(w, statement, lines)
+	case CAMT053:
+		return writeCAMT053(w, statement, lines)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
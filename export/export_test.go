@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func testStatement() (Statement, []Line) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := Statement{
+		AccountID:      42,
+		Owner:          "alice",
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: money.New(10000, "USD"),
+		ClosingBalance: money.New(-2550, "USD"),
+		GeneratedAt:    time.Date(2026, 4, 2, 9, 30, 0, 0, time.UTC),
+	}
+
+	lines := []Line{
+		{
+			EntryID:               101,
+			Amount:                money.New(-15000, "USD"),
+			RunningBalance:        money.New(-5000, "USD"),
+			TransferID:            55,
+			CounterpartyAccountID: 7,
+			CounterpartyOwner:     "bob",
+			CreatedAt:             from.Add(26 * time.Hour),
+		},
+		{
+			EntryID:               102,
+			Amount:                money.New(2450, "USD"),
+			RunningBalance:        money.New(-2550, "USD"),
+			TransferID:            56,
+			CounterpartyAccountID: 8,
+			CounterpartyOwner:     "carol",
+			CreatedAt:             from.Add(50*time.Hour + 15*time.Minute),
+		},
+		{
+			EntryID:        103,
+			Amount:         money.New(0, "USD"),
+			RunningBalance: money.New(-2550, "USD"),
+			CreatedAt:      from.Add(72 * time.Hour),
+		},
+	}
+
+	return statement, lines
+}
+
+func sliceLines(lines []Line) Lines {
+	return func(fn func(Line) error) error {
+		for _, line := range lines {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	statement, lines := testStatement()
+
+	for _, format := range []Format{CSV, OFX, CAMT053} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, format, statement, sliceLines(lines))
+			assert.NoError(t, err)
+
+			golden := filepath.Join("testdata", "statement."+string(format))
+			if *update {
+				err = os.WriteFile(golden, buf.Bytes(), 0644)
+				assert.NoError(t, err)
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
+
+func TestWriteLinesError(t *testing.T) {
+	statement, _ := testStatement()
+	errLines := errors.New("connection lost")
+
+	for _, format := range []Format{CSV, OFX, CAMT053} {
+		err := Write(&bytes.Buffer{}, format, statement, func(fn func(Line) error) error {
+			return errLines
+		})
+		assert.ErrorIs(t, err, errLines, format)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	statement, lines := testStatement()
+
+	err := Write(&bytes.Buffer{}, Format("pdf"), statement, sliceLines(lines))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "text/csv; charset=utf-8", CSV.ContentType())
+	assert.Equal(t, "application/x-ofx", OFX.ContentType())
+	assert.Equal(t, "application/xml; charset=utf-8", CAMT053.ContentType())
+}
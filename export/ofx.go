@@ -0,0 +1,105 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	ofxBankID = "SIMPLEBANK"
+	// ofxMaxNameLength is the longest NAME the OFX 2.2 schema accepts
+	ofxMaxNameLength = 32
+)
+
+func writeOFX(w io.Writer, statement Statement, lines Lines) error {
+	x := newXMLWriter(w,
+		`<?xml version="1.0" encoding="UTF-8" standalone="no"?>`,
+		`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`,
+	)
+	x.start("OFX")
+
+	x.start("SIGNONMSGSRSV1")
+	x.start("SONRS")
+	writeOFXStatus(x)
+	x.leaf("DTSERVER", formatOFXTime(statement.GeneratedAt))
+	x.leaf("LANGUAGE", "ENG")
+	x.end("SONRS")
+	x.end("SIGNONMSGSRSV1")
+
+	x.start("BANKMSGSRSV1")
+	x.start("STMTTRNRS")
+	x.leaf("TRNUID", "0")
+	writeOFXStatus(x)
+	x.start("STMTRS")
+	x.leaf("CURDEF", statement.Currency)
+	x.start("BANKACCTFROM")
+	x.leaf("BANKID", ofxBankID)
+	x.leaf("ACCTID", strconv.FormatInt(statement.AccountID, 10))
+	x.leaf("ACCTTYPE", "CHECKING")
+	x.end("BANKACCTFROM")
+
+	x.start("BANKTRANLIST")
+	x.leaf("DTSTART", formatOFXTime(statement.From))
+	x.leaf("DTEND", formatOFXTime(statement.To))
+	err := lines(func(line Line) error {
+		writeOFXTransaction(x, line)
+		return x.err
+	})
+	if err != nil {
+		return err
+	}
+	x.end("BANKTRANLIST")
+
+	x.start("LEDGERBAL")
+	x.leaf("BALAMT", statement.ClosingBalance.String())
+	x.leaf("DTASOF", formatOFXTime(statement.To))
+	x.end("LEDGERBAL")
+	x.end("STMTRS")
+	x.end("STMTTRNRS")
+	x.end("BANKMSGSRSV1")
+
+	x.end("OFX")
+	return x.flush()
+}
+
+func writeOFXStatus(x *xmlWriter) {
+	x.start("STATUS")
+	x.leaf("CODE", "0")
+	x.leaf("SEVERITY", "INFO")
+	x.end("STATUS")
+}
+
+func writeOFXTransaction(x *xmlWriter, line Line) {
+	trnType := "CREDIT"
+	if line.Amount.Minor < 0 {
+		trnType = "DEBIT"
+	}
+
+	x.start("STMTTRN")
+	x.leaf("TRNTYPE", trnType)
+	x.leaf("DTPOSTED", formatOFXTime(line.CreatedAt))
+	x.leaf("TRNAMT", line.Amount.String())
+	x.leaf("FITID", strconv.FormatInt(line.EntryID, 10))
+	if line.CounterpartyOwner != "" {
+		x.leaf("NAME", truncate(line.CounterpartyOwner, ofxMaxNameLength))
+	}
+	if line.TransferID != 0 {
+		x.leaf("MEMO", fmt.Sprintf("Transfer %d with account %d", line.TransferID, line.CounterpartyAccountID))
+	}
+	x.end("STMTTRN")
+}
+
+// formatOFXTime formats t as an OFX datetime in UTC, e.g. 20260101120000.000[0:UTC]
+func formatOFXTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xmlWriter streams an XML document token by token.
+// The first error is kept and every later call becomes a no-op, so callers check it once at the end
+type xmlWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	err     error
+}
+
+// newXMLWriter writes the prolog, one declaration or processing instruction per line,
+// and returns a writer for the document element
+func newXMLWriter(w io.Writer, prolog ...string) *xmlWriter {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	x := &xmlWriter{w: w, encoder: encoder}
+	for _, line := range prolog {
+		if x.err == nil {
+			_, x.err = io.WriteString(w, line+"\n")
+		}
+	}
+	return x
+}
+
+func (x *xmlWriter) token(token xml.Token) {
+	if x.err == nil {
+		x.err = x.encoder.EncodeToken(token)
+	}
+}
+
+func (x *xmlWriter) start(name string, attrs ...xml.Attr) {
+	x.token(xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs})
+}
+
+func (x *xmlWriter) end(name string) {
+	x.token(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+// leaf writes an element that only holds text
+func (x *xmlWriter) leaf(name string, value string, attrs ...xml.Attr) {
+	x.start(name, attrs...)
+	x.token(xml.CharData(value))
+	x.end(name)
+}
+
+// flush writes out anything buffered by the encoder, ends the file with a newline and returns the first error
+func (x *xmlWriter) flush() error {
+	if x.err == nil {
+		x.err = x.encoder.Flush()
+	}
+	if x.err == nil {
+		_, x.err = io.WriteString(x.w, "\n")
+	}
+	return x.err
+}
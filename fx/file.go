@@ -0,0 +1,72 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+)
+
+// FileProvider serves rates from a JSON file such as {"GBP/USD": 1.27}, read as exact decimals.
+// The file is read again whenever its modification time changes
+type FileProvider struct {
+	path    string
+	mutex   sync.Mutex
+	modTime time.Time
+	rates   map[string]money.Rate
+}
+
+// NewFileProvider creates a new FileProvider and loads the file once to validate it
+func NewFileProvider(path string) (*FileProvider, error) {
+	provider := &FileProvider{
+		path: path,
+	}
+
+	if err := provider.reload(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// Rate returns the rate for the pair from the latest version of the file
+func (provider *FileProvider) Rate(ctx context.Context, from string, to string) (money.Rate, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if err := provider.reload(); err != nil {
+		return money.Rate{}, err
+	}
+
+	return lookupRate(provider.rates, from, to)
+}
+
+func (provider *FileProvider) reload() error {
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return fmt.Errorf("cannot stat exchange rate file: %w", err)
+	}
+
+	if provider.rates != nil && info.ModTime().Equal(provider.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return fmt.Errorf("cannot read exchange rate file: %w", err)
+	}
+
+	// money.Rate refuses rates that are not positive
+	var rates map[string]money.Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return fmt.Errorf("cannot parse exchange rate file: %w", err)
+	}
+
+	provider.rates = rates
+	provider.modTime = info.ModTime()
+	return nil
+}
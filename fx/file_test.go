@@ -0,0 +1,45 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"GBP/USD": 1.25}`), 0o600))
+
+	provider, err := NewFileProvider(path)
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), util.GBP, util.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("1.25"), rate)
+
+	// the file is read again once it changes
+	assert.NoError(t, os.WriteFile(path, []byte(`{"GBP/USD": 1.3}`), 0o600))
+	modTime := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	rate, err = provider.Rate(context.Background(), util.GBP, util.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("1.3"), rate)
+}
+
+func TestFileProviderInvalidFile(t *testing.T) {
+	_, err := NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"GBP/USD": 0}`), 0o600))
+
+	_, err = NewFileProvider(path)
+	assert.Error(t, err)
+}
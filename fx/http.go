@@ -0,0 +1,77 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/drmanalo/simplebank/money"
+)
+
+const httpProviderTimeout = 5 * time.Second
+
+// HTTPProvider fetches rates from a Frankfurter compatible API:
+// GET {baseURL}/latest?from=GBP&to=USD returns {"rates": {"USD": 1.27}}
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+type latestRatesResponse struct {
+	Rates map[string]money.Rate `json:"rates"`
+}
+
+// NewHTTPProvider creates a new HTTPProvider
+func NewHTTPProvider(baseURL string) *HTTPProvider {
+	return &HTTPProvider{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: httpProviderTimeout,
+		},
+	}
+}
+
+// Rate fetches the latest rate for the pair
+func (provider *HTTPProvider) Rate(ctx context.Context, from string, to string) (money.Rate, error) {
+	if from == to {
+		return money.OneRate, nil
+	}
+
+	query := url.Values{}
+	query.Set("from", from)
+	query.Set("to", to)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.baseURL+"/latest?"+query.Encode(), nil)
+	if err != nil {
+		return money.Rate{}, fmt.Errorf("cannot create exchange rate request: %w", err)
+	}
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return money.Rate{}, fmt.Errorf("cannot fetch exchange rate: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusUnprocessableEntity {
+		return money.Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return money.Rate{}, fmt.Errorf("cannot fetch exchange rate: unexpected status %d", response.StatusCode)
+	}
+
+	var body latestRatesResponse
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return money.Rate{}, fmt.Errorf("cannot decode exchange rate response: %w", err)
+	}
+
+	rate, ok := body.Rates[to]
+	if !ok {
+		return money.Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+
+	return rate, nil
+}
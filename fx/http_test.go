@@ -0,0 +1,45 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/latest", r.URL.Path)
+
+		switch r.URL.Query().Get("to") {
+		case util.USD:
+			w.Write([]byte(`{"amount": 1.0, "base": "GBP", "rates": {"USD": 1.25}}`))
+		case util.CAD:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL)
+
+	rate, err := provider.Rate(context.Background(), util.GBP, util.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("1.25"), rate)
+
+	rate, err = provider.Rate(context.Background(), util.GBP, util.GBP)
+	assert.NoError(t, err)
+	assert.Equal(t, money.OneRate, rate)
+
+	_, err = provider.Rate(context.Background(), util.GBP, util.CAD)
+	assert.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = provider.Rate(context.Background(), util.GBP, util.EUR)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRateNotFound)
+}
@@ -0,0 +1,44 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+)
+
+// Supported values of the FX_PROVIDER config
+const (
+	ProviderStatic = "static"
+	ProviderFile   = "file"
+	ProviderHTTP   = "http"
+)
+
+// ErrRateNotFound is returned when a provider has no rate for the currency pair
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateProvider returns how many units of the to currency one unit of the from currency buys.
+// Rates are exact decimals, so they convert and store without float rounding
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string) (money.Rate, error)
+}
+
+// NewRateProvider creates the rate provider selected by the config
+func NewRateProvider(config util.Config) (RateProvider, error) {
+	switch config.FXProvider {
+	case "", ProviderStatic:
+		rates, err := ParseRates(config.FXRates)
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticProvider(rates), nil
+	case ProviderFile:
+		return NewFileProvider(config.FXRatesFile)
+	case ProviderHTTP:
+		return NewHTTPProvider(config.FXRatesURL), nil
+	}
+
+	return nil, fmt.Errorf("unknown exchange rate provider: %s", config.FXProvider)
+}
@@ -0,0 +1,58 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("GBP/USD=1.25, eur/usd=1.08,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]money.Rate{"GBP/USD": money.MustParseRate("1.25"), "EUR/USD": money.MustParseRate("1.08")}, rates)
+
+	rates, err = ParseRates("")
+	assert.NoError(t, err)
+	assert.Empty(t, rates)
+
+	_, err = ParseRates("GBP/USD")
+	assert.Error(t, err)
+
+	_, err = ParseRates("GBP/USD=-1")
+	assert.Error(t, err)
+}
+
+func TestStaticProvider(t *testing.T) {
+	provider := NewStaticProvider(map[string]money.Rate{"GBP/USD": money.MustParseRate("1.25")})
+
+	rate, err := provider.Rate(context.Background(), util.GBP, util.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("1.25"), rate)
+
+	rate, err = provider.Rate(context.Background(), util.USD, util.GBP)
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParseRate("0.8"), rate)
+
+	rate, err = provider.Rate(context.Background(), util.EUR, util.EUR)
+	assert.NoError(t, err)
+	assert.Equal(t, money.OneRate, rate)
+
+	_, err = provider.Rate(context.Background(), util.EUR, util.CAD)
+	assert.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestNewRateProvider(t *testing.T) {
+	provider, err := NewRateProvider(util.Config{FXRates: "GBP/USD=1.25"})
+	assert.NoError(t, err)
+	assert.IsType(t, &StaticProvider{}, provider)
+
+	provider, err = NewRateProvider(util.Config{FXProvider: ProviderHTTP, FXRatesURL: "http://localhost"})
+	assert.NoError(t, err)
+	assert.IsType(t, &HTTPProvider{}, provider)
+
+	_, err = NewRateProvider(util.Config{FXProvider: "unknown"})
+	assert.Error(t, err)
+}
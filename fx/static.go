@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/drmanalo/simplebank/money"
+)
+
+// StaticProvider serves rates from a fixed table keyed by "FROM/TO"
+type StaticProvider struct {
+	rates map[string]money.Rate
+}
+
+// NewStaticProvider creates a new StaticProvider
+func NewStaticProvider(rates map[string]money.Rate) *StaticProvider {
+	return &StaticProvider{
+		rates: rates,
+	}
+}
+
+// Rate returns the rate for the pair, falling back to the inverse of the opposite pair
+func (provider *StaticProvider) Rate(ctx context.Context, from string, to string) (money.Rate, error) {
+	return lookupRate(provider.rates, from, to)
+}
+
+// ParseRates parses a table such as "GBP/USD=1.27,EUR/USD=1.08"
+func ParseRates(s string) (map[string]money.Rate, error) {
+	rates := make(map[string]money.Rate)
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pair, value, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid exchange rate %q: expected FROM/TO=RATE", item)
+		}
+
+		rate, err := money.ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate %q: rate must be a positive number", item)
+		}
+
+		rates[strings.ToUpper(strings.TrimSpace(pair))] = rate
+	}
+
+	return rates, nil
+}
+
+func lookupRate(rates map[string]money.Rate, from string, to string) (money.Rate, error) {
+	if from == to {
+		return money.OneRate, nil
+	}
+
+	if rate, ok := rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := rates[to+"/"+from]; ok {
+		return rate.Inverse(), nil
+	}
+
+	return money.Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+}
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt.Time),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:        account.ID,
+		Owner:     account.Owner,
+		Balance:   money.New(account.Balance, account.Currency).String(),
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt.Time),
+		Status:    account.Status,
+	}
+}
+
+func convertTransfer(transfer db.Transfer, fromCurrency string, toCurrency string) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, fromCurrency).String(),
+		ToAmount:      money.New(transfer.ToAmount, toCurrency).String(),
+		ExchangeRate:  transfer.ExchangeRate.String(),
+		CreatedAt:     timestamppb.New(transfer.CreatedAt.Time),
+	}
+}
+
+func convertTransferTxResult(result db.TransferTxResult) *pb.TransferResponse {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+
+	return &pb.TransferResponse{
+		Transfer:    convertTransfer(result.Transfer, fromCurrency, toCurrency),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry, fromCurrency),
+		ToEntry:     convertEntry(result.ToEntry, toCurrency),
+	}
+}
+
+func convertScheduledTransfer(transfer db.ScheduledTransfer, currency string) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, currency).String(),
+		ExecuteAt:     timestamppb.New(transfer.ExecuteAt),
+		Status:        transfer.Status,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry, currency string) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    money.New(entry.Amount, currency).String(),
+		CreatedAt: timestamppb.New(entry.CreatedAt.Time),
+	}
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"errors"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+func unauthenticatedError(err error) error {
+	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+}
+
+// serviceError maps an error returned by the service to a gRPC status error
+func serviceError(msg string, err error) error {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation(validationErr.Field, validationErr.Err),
+		})
+	}
+
+	if errors.Is(err, service.ErrNotAccountOwner) {
+		return status.Errorf(codes.PermissionDenied, "%s: %s", msg, err)
+	}
+
+	if errors.Is(err, fx.ErrRateNotFound) {
+		return status.Errorf(codes.FailedPrecondition, "%s: %s", msg, err)
+	}
+
+	return dbError(msg, err)
+}
+
+// dbError maps an error returned by the store to a gRPC status error
+func dbError(msg string, err error) error {
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return status.Errorf(codes.NotFound, "%s: %s", msg, err)
+	}
+
+	if errors.Is(err, db.ErrInsufficientFunds) {
+		return status.Errorf(codes.FailedPrecondition, "%s: %s", msg, err)
+	}
+
+	var accountStatusErr *db.AccountStatusError
+	if errors.As(err, &accountStatusErr) {
+		return status.Errorf(codes.FailedPrecondition, "%s: %s", msg, err)
+	}
+
+	switch db.ErrorCode(err) {
+	case db.UniqueViolation:
+		return status.Errorf(codes.AlreadyExists, "%s: %s", msg, err)
+	case db.ForeignKeyViolation:
+		return status.Errorf(codes.FailedPrecondition, "%s: %s", msg, err)
+	}
+
+	return status.Errorf(codes.Internal, "%s: %s", msg, err)
+}
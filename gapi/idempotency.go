@@ -0,0 +1,123 @@
+package gapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// idempotentRequest is a request that carries an idempotency_key field
+type idempotentRequest interface {
+	proto.Message
+	GetIdempotencyKey() string
+}
+
+// protoJSON stores a response message as JSON in the idempotency_keys table
+type protoJSON struct {
+	proto.Message
+}
+
+func (msg protoJSON) MarshalJSON() ([]byte, error) {
+	return protojson.Marshal(msg.Message)
+}
+
+// checkIdempotencyKey returns the key to save with the result of the RPC, or nil when the request has none.
+// newResponse must build the same response the RPC returns from the transaction result.
+// If the key was already used, the original response is unmarshalled into replay and the RPC must return it
+func (server *Server) checkIdempotencyKey(
+	ctx context.Context,
+	username string,
+	method string,
+	req idempotentRequest,
+	newResponse func(result interface{}) proto.Message,
+	replay proto.Message,
+) (*db.IdempotencyKeyParams, bool, error) {
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		return nil, false, nil
+	}
+
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		return nil, false, status.Errorf(codes.Internal, "%s", err)
+	}
+
+	arg := &db.IdempotencyKeyParams{
+		Key:            key,
+		Username:       username,
+		RequestPath:    method,
+		RequestHash:    requestHash,
+		ResponseStatus: int32(codes.OK),
+		ExpiresAt:      time.Now().Add(server.config.IdempotencyKeyDuration),
+		NewResponse: func(result interface{}) interface{} {
+			return protoJSON{newResponse(result)}
+		},
+	}
+
+	replayed, err := server.replayIdempotencyKey(ctx, arg, replay)
+	if err != nil || replayed {
+		return nil, replayed, err
+	}
+
+	return arg, false, nil
+}
+
+// replayIdempotencyKey unmarshals the stored response of a previous request with the same key into replay.
+// It returns false when no live key exists and the request should be processed
+func (server *Server) replayIdempotencyKey(ctx context.Context, arg *db.IdempotencyKeyParams, replay proto.Message) (bool, error) {
+	idempotencyKey, found, err := server.service.StoredResponse(ctx, arg)
+	if err != nil {
+		if errors.Is(err, service.ErrIdempotencyKeyMismatch) {
+			return false, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return false, status.Errorf(codes.Internal, "failed to get idempotency key: %s", err)
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	if err := protojson.Unmarshal(idempotencyKey.ResponseBody, replay); err != nil {
+		return false, status.Errorf(codes.Internal, "cannot unmarshal idempotent response: %s", err)
+	}
+
+	return true, nil
+}
+
+// idempotencyKeyInUse handles a key stored by a concurrent request after our lookup
+func (server *Server) idempotencyKeyInUse(ctx context.Context, arg *db.IdempotencyKeyParams, replay proto.Message) error {
+	replayed, err := server.replayIdempotencyKey(ctx, arg, replay)
+	if err != nil {
+		return err
+	}
+
+	if !replayed {
+		return status.Errorf(codes.Aborted, "%s", db.ErrIdempotencyKeyInUse)
+	}
+
+	return nil
+}
+
+// hashRequest hashes the request without its idempotency key
+func hashRequest(req idempotentRequest) (string, error) {
+	msg := proto.Clone(req).ProtoReflect()
+	msg.Clear(msg.Descriptor().Fields().ByName("idempotency_key"))
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg.Interface())
+	if err != nil {
+		return "", fmt.Errorf("cannot hash request: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,100 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/registry"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/worker"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
+	config := util.Config{
+		TokenSymmetricKey:      util.RandomString(32),
+		CursorSigningKey:       util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RefreshTokenDuration:   time.Hour,
+		IdempotencyKeyDuration: time.Hour,
+		FXRates:                "GBP/USD=1.25",
+	}
+
+	// the access tokens of newContextWithBearerToken belong to an active session
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetSession(gomock.Any(), gomock.Eq(testSessionID)).
+			AnyTimes().
+			Return(db.Session{ID: testSessionID}, nil)
+	}
+
+	server, err := NewServer(config, store, taskDistributor, registry.NewCurrencyRegistry(store))
+	assert.NoError(t, err)
+
+	return server
+}
+
+// testSessionID is the session of the tokens made by newContextWithBearerToken
+var testSessionID = uuid.MustParse("7f3c9a52-58b4-4c1e-9d0a-1b2c3d4e5f60")
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	return newContextWithTokenType(t, tokenMaker, token.TokenTypeAccessToken, username, duration)
+}
+
+func newContextWithTokenType(t *testing.T, tokenMaker token.Maker, tokenType token.TokenType, username string, duration time.Duration) context.Context {
+	return newContextWithSession(t, tokenMaker, tokenType, username, testSessionID, duration)
+}
+
+func newContextWithSession(
+	t *testing.T,
+	tokenMaker token.Maker,
+	tokenType token.TokenType,
+	username string,
+	sessionID uuid.UUID,
+	duration time.Duration,
+) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, sessionID, tokenType, duration)
+	assert.NoError(t, err)
+
+	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
+	md := metadata.MD{
+		authorizationHeader: []string{
+			bearerToken,
+		},
+	}
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	assert.NoError(t, err)
+
+	user = db.User{
+		Email:          util.RandomEmail(),
+		FullName:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		Role:           util.DepositorRole,
+		Username:       util.RandomOwner(),
+	}
+	return
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		Balance:  util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Status:   db.AccountStatusActive,
+	}
+}
@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateAccountRequest(req, server.currencies)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	rsp := &pb.CreateAccountResponse{}
+	newResponse := func(result interface{}) proto.Message {
+		return &pb.CreateAccountResponse{Account: convertAccount(result.(db.Account))}
+	}
+
+	idempotencyKey, replayed, err := server.checkIdempotencyKey(ctx, authPayload.Username, pb.SimpleBank_CreateAccount_FullMethodName, req, newResponse, rsp)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return rsp, nil
+	}
+
+	account, err := server.service.CreateAccount(ctx, service.CreateAccountParams{
+		Owner:          authPayload.Username,
+		Currency:       req.GetCurrency(),
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			if err := server.idempotencyKeyInUse(ctx, idempotencyKey, rsp); err != nil {
+				return nil, err
+			}
+			return rsp, nil
+		}
+		return nil, dbError("failed to create account", err)
+	}
+
+	return newResponse(account).(*pb.CreateAccountResponse), nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest, currencies val.CurrencyChecker) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateCurrency(req.GetCurrency(), currencies); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateIdempotencyKey(req.GetIdempotencyKey()); err != nil {
+		violations = append(violations, fieldViolation("idempotency_key", err))
+	}
+
+	return violations
+}
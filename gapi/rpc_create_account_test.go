@@ -0,0 +1,226 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 0
+
+	replayReq := &pb.CreateAccountRequest{Currency: account.Currency, IdempotencyKey: "replay-key"}
+	replayed := &pb.CreateAccountResponse{Account: &pb.Account{Id: account.ID, Owner: user.Username, Currency: account.Currency}}
+	replayBody, err := protojson.Marshal(replayed)
+	assert.NoError(t, err)
+	replayHash, err := hashRequest(replayReq)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Balance:  0,
+						Currency: account.Currency,
+						Owner:    user.Username,
+					},
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, account.ID, res.GetAccount().GetId())
+				assert.Equal(t, user.Username, res.GetAccount().GetOwner())
+				assert.Equal(t, money.New(0, account.Currency).String(), res.GetAccount().GetBalance())
+				assert.Equal(t, account.Currency, res.GetAccount().GetCurrency())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ExpiredToken",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "XYZ"},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, &pgconn.PgError{Code: db.ForeignKeyViolation})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "DuplicateCurrency",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, db.ErrUniqueVioloation)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "IdempotencyKeyReplay",
+			req:  replayReq,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{Username: user.Username, Key: replayReq.IdempotencyKey})).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestPath:  pb.SimpleBank_CreateAccount_FullMethodName,
+						RequestHash:  replayHash,
+						ResponseBody: replayBody,
+					}, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.NoError(t, err)
+				assert.True(t, proto.Equal(replayed, res))
+			},
+		},
+		{
+			name: "IdempotencyKeyFromOtherMethod",
+			req:  replayReq,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestPath: pb.SimpleBank_Transfer_FullMethodName,
+						RequestHash: replayHash,
+					}, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.CreateAccount(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListAccountsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if req.GetPageId() == 0 {
+		return server.listAccountsAfter(ctx, authPayload.Username, req)
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, dbError("failed to list accounts", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, len(accounts)),
+	}
+	for i, account := range accounts {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	return rsp, nil
+}
+
+// listAccountsAfter returns the page of accounts following req.Cursor in (created_at, id) order
+func (server *Server) listAccountsAfter(ctx context.Context, owner string, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	scope := pagination.AccountsScope(owner)
+
+	var after pagination.Cursor
+	if req.GetCursor() != "" {
+		var err error
+		after, err = server.cursorSigner.Decode(scope, req.GetCursor())
+		if err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("cursor", err)})
+		}
+	}
+
+	// fetch one extra row to find out whether there is another page
+	arg := db.ListAccountsAfterParams{
+		Owner:          owner,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		RowLimit:       req.GetPageSize() + 1,
+	}
+
+	accounts, err := server.store.ListAccountsAfter(ctx, arg)
+	if err != nil {
+		return nil, dbError("failed to list accounts", err)
+	}
+
+	accounts, next := pagination.Page(accounts, req.GetPageSize(), func(account db.Account) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: account.CreatedAt.Time, ID: account.ID}
+	})
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, len(accounts)),
+	}
+	for i, account := range accounts {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	if next != nil {
+		rsp.NextCursor = server.cursorSigner.Encode(scope, *next)
+	}
+	return rsp, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageId() < 0 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be at least 1")))
+	}
+
+	if req.GetPageId() != 0 && req.GetCursor() != "" {
+		violations = append(violations, fieldViolation("cursor", fmt.Errorf("cannot be used with page_id")))
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 10 {
+		violations = append(violations, fieldViolation("page_size", fmt.Errorf("must be between 5 and 10")))
+	}
+
+	return violations
+}
@@ -0,0 +1,253 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListAccountsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	n := int32(5)
+	accounts := make([]db.Account, n+1)
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range accounts {
+		accounts[i] = randomAccount(user.Username)
+		accounts[i].ID = int64(i + 1)
+		accounts[i].CreatedAt = pgtype.Timestamptz{Time: createdAt.Add(time.Duration(i) * time.Minute), Valid: true}
+	}
+
+	after := pagination.Cursor{CreatedAt: createdAt, ID: 42}
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		buildRequest  func(server *Server) *pb.ListAccountsRequest
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error)
+	}{
+		{
+			name: "PageID",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageId: 2, PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{
+					Owner:  user.Username,
+					Limit:  n,
+					Offset: n,
+				}
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts[:n], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, res.GetAccounts(), int(n))
+				assert.Empty(t, res.GetNextCursor())
+			},
+		},
+		{
+			name: "FirstPage",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{
+					Owner:    user.Username,
+					RowLimit: n + 1,
+				}
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, res.GetAccounts(), int(n))
+
+				next, err := server.cursorSigner.Decode(pagination.AccountsScope(user.Username), res.GetNextCursor())
+				assert.NoError(t, err)
+				assert.Equal(t, accounts[n-1].ID, next.ID)
+			},
+		},
+		{
+			name: "NextPage",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				cursor := server.cursorSigner.Encode(pagination.AccountsScope(user.Username), after)
+				return &pb.ListAccountsRequest{PageSize: n, Cursor: cursor}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{
+					Owner:          user.Username,
+					AfterCreatedAt: after.CreatedAt,
+					AfterID:        after.ID,
+					RowLimit:       n + 1,
+				}
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts[:1], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, res.GetAccounts(), 1)
+				assert.Empty(t, res.GetNextCursor())
+			},
+		},
+		{
+			name: "OtherUsersCursor",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				cursor := server.cursorSigner.Encode(pagination.AccountsScope("someone_else"), after)
+				return &pb.ListAccountsRequest{PageSize: n, Cursor: cursor}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "CursorWithPageID",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				cursor := server.cursorSigner.Encode(pagination.AccountsScope(user.Username), after)
+				return &pb.ListAccountsRequest{PageId: 1, PageSize: n, Cursor: cursor}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "RefreshToken",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithTokenType(t, tokenMaker, token.TokenTypeRefreshToken, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "LoggedOutSession",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithSession(t, tokenMaker, token.TokenTypeAccessToken, user.Username, sessionID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.Session{ID: sessionID, Username: user.Username, IsBlocked: true}, nil)
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			buildRequest: func(server *Server) *pb.ListAccountsRequest {
+				return &pb.ListAccountsRequest{PageSize: n}
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.ListAccountsResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.ListAccounts(ctx, tc.buildRequest(server))
+			tc.checkResponse(t, server, res, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+func (server *Server) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.TransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	amount, violations := validateTransferRequest(req, server.currencies)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	rsp := &pb.TransferResponse{}
+	newResponse := func(result interface{}) proto.Message {
+		if scheduled, ok := result.(db.ScheduledTransfer); ok {
+			return &pb.TransferResponse{ScheduledTransfer: convertScheduledTransfer(scheduled, req.GetCurrency())}
+		}
+		return convertTransferTxResult(result.(db.TransferTxResult))
+	}
+
+	idempotencyKey, replayed, err := server.checkIdempotencyKey(ctx, authPayload.Username, pb.SimpleBank_Transfer_FullMethodName, req, newResponse, rsp)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return rsp, nil
+	}
+
+	arg := service.TransferParams{
+		Username:       authPayload.Username,
+		FromAccountID:  req.GetFromAccountId(),
+		ToAccountID:    req.GetToAccountId(),
+		Amount:         amount,
+		CrossCurrency:  req.GetCrossCurrency(),
+		IdempotencyKey: idempotencyKey,
+	}
+	if req.ExecuteAt != nil {
+		arg.ExecuteAt = req.GetExecuteAt().AsTime()
+	}
+
+	result, err := server.service.Transfer(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+			if err := server.idempotencyKeyInUse(ctx, idempotencyKey, rsp); err != nil {
+				return nil, err
+			}
+			return rsp, nil
+		}
+		return nil, serviceError("failed to transfer", err)
+	}
+
+	if result.Scheduled != nil {
+		return newResponse(*result.Scheduled).(*pb.TransferResponse), nil
+	}
+
+	return newResponse(result.TransferTxResult).(*pb.TransferResponse), nil
+}
+
+// validateTransferRequest also parses the decimal amount, which is only valid when there are no violations
+func validateTransferRequest(req *pb.TransferRequest, currencies val.CurrencyChecker) (amount money.Amount, violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if err := val.ValidateIdempotencyKey(req.GetIdempotencyKey()); err != nil {
+		violations = append(violations, fieldViolation("idempotency_key", err))
+	}
+
+	if req.ExecuteAt != nil {
+		if err := req.GetExecuteAt().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("execute_at", err))
+		}
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency(), currencies); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+		return amount, violations
+	}
+
+	amount, err := money.Parse(req.GetAmount(), req.GetCurrency())
+	if err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	} else if !amount.IsPositive() {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must be greater than 0")))
+	}
+
+	return amount, violations
+}
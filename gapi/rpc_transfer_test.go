@@ -0,0 +1,492 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+
+	account1.Currency = util.GBP
+	account2.Currency = util.GBP
+	account3.Currency = util.USD
+	amount := "0.10"
+	minor := int64(10)
+
+	account4 := randomAccount(user2.Username)
+	account4.Currency = util.EUR
+
+	executeAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	scheduled := db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ExecuteAt:     executeAt,
+		Status:        db.ScheduledTransferPending,
+	}
+
+	replayReq := &pb.TransferRequest{
+		FromAccountId:  account1.ID,
+		ToAccountId:    account2.ID,
+		Amount:         amount,
+		Currency:       util.GBP,
+		IdempotencyKey: "replay-key",
+	}
+	replayed := &pb.TransferResponse{Transfer: &pb.Transfer{Id: 42, Amount: amount, ToAmount: amount, ExchangeRate: "1"}}
+	replayBody, err := protojson.Marshal(replayed)
+	assert.NoError(t, err)
+	replayHash, err := hashRequest(replayReq)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *pb.TransferRequest
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.TransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferTxParams{
+					Amount:        minor,
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: minor},
+					FromAccount: account1,
+					ToAccount:   account2,
+					FromEntry:   db.Entry{AccountID: account1.ID, Amount: -minor},
+					ToEntry:     db.Entry{AccountID: account2.ID, Amount: minor},
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, amount, res.GetTransfer().GetAmount())
+				assert.Equal(t, "-0.10", res.GetFromEntry().GetAmount())
+				assert.Equal(t, amount, res.GetToEntry().GetAmount())
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account3.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NegativeAmount",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        "-" + amount,
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "TooManyDecimalPlaces",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        "0.105",
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidAmount",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        "ten",
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ZeroAmount",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        "0.00",
+				Currency:      util.GBP,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "CrossCurrency",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account3.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+				CrossCurrency: true,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferTxParams{
+					Amount:        minor,
+					FromAccountID: account1.ID,
+					ToAccountID:   account3.ID,
+					ToAmount:      12,
+					ExchangeRate:  money.MustParseRate("1.25"),
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: minor, ToAmount: 12, ExchangeRate: money.MustParseRate("1.25")},
+					FromAccount: account1,
+					ToAccount:   account3,
+					FromEntry:   db.Entry{AccountID: account1.ID, Amount: -minor},
+					ToEntry:     db.Entry{AccountID: account3.ID, Amount: 12},
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, amount, res.GetTransfer().GetAmount())
+				assert.Equal(t, "0.12", res.GetTransfer().GetToAmount())
+				assert.Equal(t, "1.25", res.GetTransfer().GetExchangeRate())
+				assert.Equal(t, "0.12", res.GetToEntry().GetAmount())
+			},
+		},
+		{
+			name: "CrossCurrencyRateNotFound",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account4.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+				CrossCurrency: true,
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account4.ID)).Times(1).Return(account4, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "Scheduled",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+				ExecuteAt:     timestamppb.New(executeAt),
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateScheduledTransferTxParams{
+					CreateScheduledTransferParams: db.CreateScheduledTransferParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        minor,
+						ExecuteAt:     executeAt,
+					},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(scheduled, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Nil(t, res.GetTransfer())
+				assert.Equal(t, scheduled.ID, res.GetScheduledTransfer().GetId())
+				assert.Equal(t, amount, res.GetScheduledTransfer().GetAmount())
+				assert.Equal(t, executeAt, res.GetScheduledTransfer().GetExecuteAt().AsTime())
+				assert.Equal(t, db.ScheduledTransferPending, res.GetScheduledTransfer().GetStatus())
+			},
+		},
+		{
+			name: "ExecuteAtInPast",
+			req: &pb.TransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.GBP,
+				ExecuteAt:     timestamppb.New(time.Now().Add(-time.Minute)),
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "IdempotencyKeyReplay",
+			req:  replayReq,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetIdempotencyKeyParams{Username: user1.Username, Key: replayReq.IdempotencyKey}
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Key:          replayReq.IdempotencyKey,
+						Username:     user1.Username,
+						RequestPath:  pb.SimpleBank_Transfer_FullMethodName,
+						RequestHash:  replayHash,
+						ResponseBody: replayBody,
+					}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.True(t, proto.Equal(replayed, res))
+			},
+		},
+		{
+			name: "IdempotencyKeyMismatch",
+			req:  replayReq,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Key:         replayReq.IdempotencyKey,
+						Username:    user1.Username,
+						RequestPath: pb.SimpleBank_Transfer_FullMethodName,
+						RequestHash: "other",
+					}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "IdempotencyKeySaved",
+			req: &pb.TransferRequest{
+				FromAccountId:  account1.ID,
+				ToAccountId:    account2.ID,
+				Amount:         amount,
+				Currency:       util.GBP,
+				IdempotencyKey: "new-key",
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						assert.Equal(t, "new-key", arg.IdempotencyKey.Key)
+						assert.Equal(t, pb.SimpleBank_Transfer_FullMethodName, arg.IdempotencyKey.RequestPath)
+						return db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "IdempotencyKeyInUse",
+			req:  replayReq,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrIdempotencyKeyInUse)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferResponse, err error) {
+				assert.Equal(t, codes.Aborted, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil)
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.Transfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package gapi
+
+import (
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/pagination"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/registry"
+	"github.com/drmanalo/simplebank/service"
+	"github.com/drmanalo/simplebank/token"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/worker"
+)
+
+// Server serves gRPC requests for our banking service
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config          util.Config
+	store           db.Store
+	tokenMaker      token.Maker
+	taskDistributor worker.TaskDistributor
+	service         *service.Service
+	currencies      *registry.CurrencyRegistry
+	cursorSigner    *pagination.Signer
+}
+
+// NewServer creates a new gRPC server
+func NewServer(
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+	currencies *registry.CurrencyRegistry,
+) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+	}
+
+	cursorSigner, err := pagination.NewSigner(config.CursorSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cursor signer: %w", err)
+	}
+
+	server := &Server{
+		config:          config,
+		store:           store,
+		tokenMaker:      tokenMaker,
+		taskDistributor: taskDistributor,
+		service:         service.New(store, rateProvider),
+		currencies:      currencies,
+		cursorSigner:    cursorSigner,
+	}
+
+	return server, nil
+}
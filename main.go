@@ -2,18 +2,44 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
 	"os"
+	"time"
+	// standing orders load IANA time zones, which the alpine image doesn't ship
+	_ "time/tzdata"
 
 	"github.com/drmanalo/simplebank/api"
+	"github.com/drmanalo/simplebank/db/migration"
 	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/doc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/gapi"
+	"github.com/drmanalo/simplebank/mail"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/pb"
+	"github.com/drmanalo/simplebank/registry"
+	"github.com/drmanalo/simplebank/scheduler"
 	"github.com/drmanalo/simplebank/util"
+	"github.com/drmanalo/simplebank/worker"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply the database migrations and exit")
+	flag.Parse()
+
 	config, err := util.LoadConfig(".")
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot load config")
@@ -23,13 +49,196 @@ func main() {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
+	runDBMigration(config.DBSource, config.MigrationLockTimeout)
+	if *migrateOnly {
+		return
+	}
+
 	connPool, err := pgxpool.New(context.Background(), config.DBSource)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot connect to db")
 	}
 
 	store := db.NewStore(connPool)
-	server := api.NewServer(store)
+
+	redisOpt := asynq.RedisClientOpt{
+		Addr: config.RedisAddress,
+	}
+
+	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
+
+	currencies := registry.NewCurrencyRegistry(store)
+	err = currencies.Load(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load currencies")
+	}
+	money.SetCurrencySource(currencies)
+
+	go currencies.Refresh(context.Background(), config.CurrencyRefreshInterval)
+	go runTaskProcessor(config, redisOpt, store)
+	go runTransferScheduler(config, store)
+	go runStandingOrderWorker(config, store)
+	go runGatewayServer(config, store, taskDistributor, currencies)
+	go runGrpcServer(config, store, taskDistributor, currencies)
+	runGinServer(config, store, taskDistributor, currencies)
+}
+
+// runDBMigration applies the embedded migrations that haven't run yet.
+// Replicas starting together wait on the migration lock instead of racing,
+// and a database left dirty by a failed migration stops the server from starting
+func runDBMigration(dbSource string, lockTimeout time.Duration) {
+	m, err := migration.New(dbSource, lockTimeout)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create new migrate instance")
+	}
+	defer m.Close()
+
+	err = m.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		var dirtyErr migrate.ErrDirty
+		if errors.As(err, &dirtyErr) {
+			log.Fatal().Int("version", dirtyErr.Version).Msg("db is dirty after a failed migration; fix the schema and force the version before starting")
+		}
+		if errors.Is(err, migrate.ErrLockTimeout) {
+			log.Fatal().Dur("lock_timeout", lockTimeout).Msg("gave up waiting for another instance to finish migrating")
+		}
+		log.Fatal().Err(err).Msg("failed to run migrate up")
+	}
+
+	log.Info().Msg("db migrated successfully")
+}
+
+func runGrpcServer(
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+	currencies *registry.CurrencyRegistry,
+) {
+	server, err := gapi.NewServer(config, store, taskDistributor, currencies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create gRPC server")
+	}
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create listener")
+	}
+
+	log.Info().Msgf("start gRPC server at %s", listener.Addr().String())
+	err = grpcServer.Serve(listener)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot start gRPC server")
+	}
+}
+
+func runGatewayServer(
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+	currencies *registry.CurrencyRegistry,
+) {
+	server, err := gapi.NewServer(config, store, taskDistributor, currencies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create gRPC server")
+	}
+
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot register handler server")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", grpcMux)
+
+	swaggerHandler := http.StripPrefix("/swagger/", http.FileServer(http.FS(doc.SwaggerFS())))
+	mux.Handle("/swagger/", swaggerHandler)
+
+	listener, err := net.Listen("tcp", config.GatewayServerAddress)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create listener")
+	}
+
+	log.Info().Msgf("start HTTP gateway server at %s", listener.Addr().String())
+	err = http.Serve(listener, mux)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot start HTTP gateway server")
+	}
+}
+
+func runTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, store db.Store) {
+	mailer := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
+	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, config, store, mailer)
+
+	log.Info().Msg("start task processor")
+	err := taskProcessor.Start()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to start task processor")
+	}
+}
+
+// runTransferScheduler executes scheduled transfers as they fall due.
+// Every replica runs one, and they never claim the same transfer
+func runTransferScheduler(config util.Config, store db.Store) {
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create exchange rate provider")
+	}
+
+	retry := db.RetryPolicy{
+		MaxRetries: config.TransferMaxRetries,
+		Backoff:    config.TransferRetryBackoff,
+	}
+
+	log.Info().Msg("start transfer scheduler")
+	scheduler.NewTransferScheduler(store, rateProvider, retry, scheduler.SystemClock).
+		Run(context.Background(), config.TransferSchedulerInterval)
+}
+
+// runStandingOrderWorker pays the occurrences of standing orders as they fall due
+func runStandingOrderWorker(config util.Config, store db.Store) {
+	rateProvider, err := fx.NewRateProvider(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create exchange rate provider")
+	}
+
+	retry := db.RetryPolicy{
+		MaxRetries: config.StandingOrderMaxRetries,
+		Backoff:    config.StandingOrderRetryBackoff,
+	}
+
+	log.Info().Msg("start standing order worker")
+	scheduler.NewStandingOrderWorker(store, rateProvider, retry, scheduler.SystemClock).
+		Run(context.Background(), config.StandingOrderInterval)
+}
+
+func runGinServer(
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+	currencies *registry.CurrencyRegistry,
+) {
+	server, err := api.NewServer(config, store, taskDistributor, currencies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create server")
+	}
 
 	err = server.Start(config.HTTPServerAddress)
 	if err != nil {
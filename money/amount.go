@@ -0,0 +1,167 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflow")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Amount is a sum of money stored as an integer number of minor units, e.g. pence for GBP
+type Amount struct {
+	Minor    int64
+	Currency string
+}
+
+// New creates an Amount from minor units
+func New(minor int64, currency string) Amount {
+	return Amount{
+		Minor:    minor,
+		Currency: currency,
+	}
+}
+
+// Parse parses a decimal string such as "12.50" in the given currency.
+// More decimal places than the currency allows is an error rather than being rounded away
+func Parse(value string, currency string) (Amount, error) {
+	meta, err := LookupCurrency(currency)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	s := strings.TrimSpace(value)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	if len(fraction) > meta.Exponent {
+		return Amount{}, fmt.Errorf("%w: %q has more than %d decimal places for %s", ErrInvalidAmount, value, meta.Exponent, currency)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", meta.Exponent-len(fraction))
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w: %q", ErrOverflow, value)
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return New(minor, currency), nil
+}
+
+// Add returns a + b, failing on mismatched currencies or int64 overflow
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.Currency != b.Currency {
+		return Amount{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+	}
+
+	sum := a.Minor + b.Minor
+	if (b.Minor > 0 && sum < a.Minor) || (b.Minor < 0 && sum > a.Minor) {
+		return Amount{}, ErrOverflow
+	}
+
+	return New(sum, a.Currency), nil
+}
+
+// Sub returns a - b, failing on mismatched currencies or int64 overflow
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b.Minor == math.MinInt64 {
+		return Amount{}, ErrOverflow
+	}
+
+	return a.Add(New(-b.Minor, b.Currency))
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (a Amount) IsPositive() bool {
+	return a.Minor > 0
+}
+
+// Convert converts the amount into another currency at the given rate.
+// The rate is in major units and the result is rounded half to even
+func (a Amount) Convert(currency string, rate Rate) (Amount, error) {
+	from, err := LookupCurrency(a.Currency)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	to, err := LookupCurrency(currency)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	converted := new(big.Rat).SetInt64(a.Minor)
+	converted.Mul(converted, rate.rat())
+
+	converted.Mul(converted, new(big.Rat).SetInt(pow10(to.Exponent)))
+	converted.Quo(converted, new(big.Rat).SetInt(pow10(from.Exponent)))
+
+	minor := roundHalfEven(converted)
+	if !minor.IsInt64() {
+		return Amount{}, ErrOverflow
+	}
+
+	return New(minor.Int64(), currency), nil
+}
+
+// String formats the amount as a decimal string such as "12.50"
+func (a Amount) String() string {
+	exponent := 0
+	if meta, err := LookupCurrency(a.Currency); err == nil {
+		exponent = meta.Exponent
+	}
+
+	sign := ""
+	// the magnitude of math.MinInt64 does not fit in an int64, so format it as unsigned
+	magnitude := uint64(a.Minor)
+	if a.Minor < 0 {
+		sign = "-"
+		magnitude = uint64(-(a.Minor + 1)) + 1
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	point := len(digits) - exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON encodes the amount as a decimal string
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if _, err := LookupCurrency(a.Currency); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(a.String())
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		minor    int64
+		err      error
+	}{
+		{value: "12.50", currency: "GBP", minor: 1250},
+		{value: "12.5", currency: "GBP", minor: 1250},
+		{value: "12", currency: "GBP", minor: 1200},
+		{value: "0.01", currency: "USD", minor: 1},
+		{value: "-3.10", currency: "EUR", minor: -310},
+		{value: "1500", currency: "JPY", minor: 1500},
+		{value: "1.234", currency: "BHD", minor: 1234},
+		{value: "1.5", currency: "JPY", err: ErrInvalidAmount},
+		{value: "1.234", currency: "GBP", err: ErrInvalidAmount},
+		{value: "", currency: "GBP", err: ErrInvalidAmount},
+		{value: ".5", currency: "GBP", err: ErrInvalidAmount},
+		{value: "1e3", currency: "GBP", err: ErrInvalidAmount},
+		{value: "99999999999999999999", currency: "GBP", err: ErrOverflow},
+		{value: "1.00", currency: "XXX", err: ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		amount, err := Parse(tc.value, tc.currency)
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err, tc.value)
+			continue
+		}
+
+		assert.NoError(t, err, tc.value)
+		assert.Equal(t, New(tc.minor, tc.currency), amount)
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "12.50", New(1250, "GBP").String())
+	assert.Equal(t, "0.05", New(5, "USD").String())
+	assert.Equal(t, "-0.05", New(-5, "USD").String())
+	assert.Equal(t, "1500", New(1500, "JPY").String())
+	assert.Equal(t, "0.001", New(1, "BHD").String())
+	assert.Equal(t, "-92233720368547758.08", New(math.MinInt64, "USD").String())
+}
+
+func TestAddSub(t *testing.T) {
+	sum, err := New(150, "GBP").Add(New(275, "GBP"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(425, "GBP"), sum)
+
+	diff, err := New(150, "GBP").Sub(New(275, "GBP"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(-125, "GBP"), diff)
+
+	_, err = New(1, "GBP").Add(New(1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, "GBP").Add(New(1, "GBP"))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "GBP").Sub(New(1, "GBP"))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, "GBP").Sub(New(math.MinInt64, "GBP"))
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestConvert(t *testing.T) {
+	converted, err := New(1000, "GBP").Convert("USD", MustParseRate("1.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(1250, "USD"), converted)
+
+	// banker's rounding: halves go to the even neighbour
+	converted, err = New(10, "GBP").Convert("USD", MustParseRate("1.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(12, "USD"), converted)
+
+	converted, err = New(30, "GBP").Convert("USD", MustParseRate("1.05"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(32, "USD"), converted)
+
+	// exact halves that a float64 product lands just below
+	converted, err = New(50, "GBP").Convert("USD", MustParseRate("1.15"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(58, "USD"), converted)
+
+	converted, err = New(150, "GBP").Convert("EUR", MustParseRate("1.17"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(176, "EUR"), converted)
+
+	// exponents differ between USD and JPY
+	converted, err = New(1000, "USD").Convert("JPY", MustParseRate("150"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(1500, "JPY"), converted)
+
+	converted, err = New(1500, "JPY").Convert("BHD", MustParseRate("0.0025"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(3750, "BHD"), converted)
+
+	converted, err = New(-10, "GBP").Convert("USD", MustParseRate("1.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(-12, "USD"), converted)
+
+	_, err = New(math.MaxInt64, "USD").Convert("JPY", MustParseRate("1000"))
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(100, "USD").Convert("XXX", OneRate)
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Balance Amount `json:"balance"`
+	}{New(1250, "GBP")})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"balance": "12.50"}`, string(data))
+
+	_, err = json.Marshal(New(1, "XXX"))
+	assert.Error(t, err)
+}
+
+func TestLookupCurrency(t *testing.T) {
+	currency, err := LookupCurrency("JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, Currency{Code: "JPY", Numeric: "392", Exponent: 0}, currency)
+
+	_, err = LookupCurrency("XXX")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+type fakeSource map[string]int
+
+func (s fakeSource) Exponent(code string) (int, bool) {
+	exponent, ok := s[code]
+	return exponent, ok
+}
+
+func TestLookupCurrencySource(t *testing.T) {
+	SetCurrencySource(fakeSource{"PLN": 2, "JPY": 0})
+	defer SetCurrencySource(nil)
+
+	currency, err := LookupCurrency("PLN")
+	assert.NoError(t, err)
+	assert.Equal(t, Currency{Code: "PLN", Exponent: 2}, currency)
+
+	amount, err := Parse("10.25", "PLN")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1025), amount.Minor)
+	assert.Equal(t, "10.25", amount.String())
+
+	// the ISO numeric code is kept for currencies in both
+	currency, err = LookupCurrency("JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, Currency{Code: "JPY", Numeric: "392", Exponent: 0}, currency)
+
+	// the ISO table still backs currencies the source doesn't know
+	currency, err = LookupCurrency("BHD")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, currency.Exponent)
+
+	_, err = LookupCurrency("XXX")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
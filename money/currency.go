@@ -0,0 +1,73 @@
+package money
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Currency holds the ISO 4217 metadata of a currency
+type Currency struct {
+	Code     string
+	Numeric  string
+	Exponent int
+}
+
+// currencies lists the ISO 4217 currencies we know the minor units of
+var currencies = map[string]Currency{
+	"AUD": {Code: "AUD", Numeric: "036", Exponent: 2},
+	"BHD": {Code: "BHD", Numeric: "048", Exponent: 3},
+	"CAD": {Code: "CAD", Numeric: "124", Exponent: 2},
+	"CHF": {Code: "CHF", Numeric: "756", Exponent: 2},
+	"CNY": {Code: "CNY", Numeric: "156", Exponent: 2},
+	"EUR": {Code: "EUR", Numeric: "978", Exponent: 2},
+	"GBP": {Code: "GBP", Numeric: "826", Exponent: 2},
+	"HKD": {Code: "HKD", Numeric: "344", Exponent: 2},
+	"JPY": {Code: "JPY", Numeric: "392", Exponent: 0},
+	"KRW": {Code: "KRW", Numeric: "410", Exponent: 0},
+	"KWD": {Code: "KWD", Numeric: "414", Exponent: 3},
+	"NZD": {Code: "NZD", Numeric: "554", Exponent: 2},
+	"OMR": {Code: "OMR", Numeric: "512", Exponent: 3},
+	"SEK": {Code: "SEK", Numeric: "752", Exponent: 2},
+	"SGD": {Code: "SGD", Numeric: "702", Exponent: 2},
+	"USD": {Code: "USD", Numeric: "840", Exponent: 2},
+}
+
+// CurrencySource provides the minor units of the currencies the bank has configured,
+// so currencies added at runtime can be parsed and formatted without a redeploy
+type CurrencySource interface {
+	Exponent(code string) (int, bool)
+}
+
+var (
+	sourceMutex sync.RWMutex
+	source      CurrencySource
+)
+
+// SetCurrencySource makes LookupCurrency consult source before the ISO 4217 table
+func SetCurrencySource(s CurrencySource) {
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+
+	source = s
+}
+
+// LookupCurrency returns the metadata of the currency code, taking the exponent
+// from the currency source when it knows the code
+func LookupCurrency(code string) (Currency, error) {
+	sourceMutex.RLock()
+	s := source
+	sourceMutex.RUnlock()
+
+	currency, ok := currencies[code]
+	if s != nil {
+		if exponent, found := s.Exponent(code); found {
+			return Currency{Code: code, Numeric: currency.Numeric, Exponent: exponent}, nil
+		}
+	}
+
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	return currency, nil
+}
@@ -0,0 +1,159 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateDecimals is the number of decimal places rates are kept to, as in transfers.exchange_rate
+const RateDecimals = 10
+
+var (
+	ErrInvalidRate = errors.New("invalid exchange rate")
+
+	rateScale = pow10(RateDecimals)
+)
+
+// Rate is an exchange rate in major units, held exactly as an integer number of 10^-RateDecimals
+type Rate struct {
+	scaled int64
+}
+
+// OneRate is the rate between a currency and itself
+var OneRate = Rate{scaled: rateScale.Int64()}
+
+// ParseRate parses a positive decimal string such as "1.27".
+// Digits beyond RateDecimals decimal places are rounded half to even
+func ParseRate(value string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || r.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("%w: %q must be a positive number", ErrInvalidRate, value)
+	}
+
+	scaled := roundHalfEven(r.Mul(r, new(big.Rat).SetInt(rateScale)))
+	if !scaled.IsInt64() || scaled.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("%w: %q is out of range", ErrInvalidRate, value)
+	}
+
+	return Rate{scaled: scaled.Int64()}, nil
+}
+
+// MustParseRate is like ParseRate but panics if the value is invalid
+func MustParseRate(value string) Rate {
+	rate, err := ParseRate(value)
+	if err != nil {
+		panic(err)
+	}
+	return rate
+}
+
+// IsZero reports whether the rate is unset
+func (rate Rate) IsZero() bool {
+	return rate.scaled == 0
+}
+
+// Inverse returns the rate of the opposite pair, rounded half to even
+func (rate Rate) Inverse() Rate {
+	if rate.scaled == 0 {
+		return Rate{}
+	}
+
+	one := new(big.Int).Mul(rateScale, rateScale)
+	return Rate{scaled: roundHalfEven(new(big.Rat).SetFrac(one, big.NewInt(rate.scaled))).Int64()}
+}
+
+// String formats the rate as a decimal string without trailing zeros, such as "1.27"
+func (rate Rate) String() string {
+	digits := strconv.FormatInt(rate.scaled, 10)
+	if len(digits) <= RateDecimals {
+		digits = strings.Repeat("0", RateDecimals-len(digits)+1) + digits
+	}
+
+	point := len(digits) - RateDecimals
+	fraction := strings.TrimRight(digits[point:], "0")
+	if fraction == "" {
+		return digits[:point]
+	}
+
+	return digits[:point] + "." + fraction
+}
+
+// MarshalJSON encodes the rate as a decimal string
+func (rate Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rate.String())
+}
+
+// UnmarshalJSON decodes a rate from a decimal string or a JSON number, without going through a float
+func (rate *Rate) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return err
+	}
+
+	*rate = parsed
+	return nil
+}
+
+// Scan reads the rate from a numeric column
+func (rate *Rate) Scan(src interface{}) error {
+	var value string
+	switch src := src.(type) {
+	case string:
+		value = src
+	case []byte:
+		value = string(src)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidRate, src)
+	}
+
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return err
+	}
+
+	*rate = parsed
+	return nil
+}
+
+// Value writes the rate to a numeric column
+func (rate Rate) Value() (driver.Value, error) {
+	return rate.String(), nil
+}
+
+func (rate Rate) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(rate.scaled), rateScale)
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundHalfEven rounds r to the nearest integer, and halves to the even neighbour
+func roundHalfEven(r *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	// compare twice the remainder to the denominator to find which neighbour is nearer
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	cmp := twice.Cmp(r.Denom())
+
+	if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+		if r.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo
+}
@@ -0,0 +1,65 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("1.2700")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.27", rate.String())
+
+	rate, err = ParseRate("150")
+	assert.NoError(t, err)
+	assert.Equal(t, "150", rate.String())
+
+	// rates are kept to RateDecimals decimal places
+	rate, err = ParseRate("0.000000000125")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0000000001", rate.String())
+
+	for _, value := range []string{"", "abc", "0", "-1.2", "1e30"} {
+		_, err = ParseRate(value)
+		assert.ErrorIs(t, err, ErrInvalidRate, value)
+	}
+}
+
+func TestRateInverse(t *testing.T) {
+	assert.Equal(t, "0.8", MustParseRate("1.25").Inverse().String())
+	assert.Equal(t, "0.7874015748", MustParseRate("1.27").Inverse().String())
+	assert.Equal(t, OneRate, OneRate.Inverse())
+	assert.True(t, Rate{}.Inverse().IsZero())
+}
+
+func TestRateJSON(t *testing.T) {
+	data, err := json.Marshal(MustParseRate("1.15"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `"1.15"`, string(data))
+
+	// numbers are read from their decimal text rather than through a float64
+	var rates map[string]Rate
+	err = json.Unmarshal([]byte(`{"GBP/USD": 1.15, "EUR/USD": "1.08"}`), &rates)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Rate{"GBP/USD": MustParseRate("1.15"), "EUR/USD": MustParseRate("1.08")}, rates)
+
+	err = json.Unmarshal([]byte(`{"GBP/USD": 0}`), &rates)
+	assert.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestRateScan(t *testing.T) {
+	var rate Rate
+	assert.NoError(t, rate.Scan("1.2500000000"))
+	assert.Equal(t, MustParseRate("1.25"), rate)
+
+	assert.NoError(t, rate.Scan([]byte("0.8")))
+	assert.Equal(t, MustParseRate("0.8"), rate)
+
+	assert.ErrorIs(t, rate.Scan(nil), ErrInvalidRate)
+
+	value, err := MustParseRate("1.27").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.27", value)
+}
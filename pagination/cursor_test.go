@@ -0,0 +1,79 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drmanalo/simplebank/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	assert.NoError(t, err)
+
+	cursor := Cursor{
+		CreatedAt: time.Date(2026, 5, 4, 3, 2, 1, 123456000, time.UTC),
+		ID:        util.RandomInt(1, 1000),
+	}
+
+	value := signer.Encode("accounts/alice", cursor)
+	assert.NotEmpty(t, value)
+
+	decoded, err := signer.Decode("accounts/alice", value)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestCursorWrongScope(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	assert.NoError(t, err)
+
+	value := signer.Encode("accounts/alice", Cursor{CreatedAt: time.Now(), ID: 1})
+
+	_, err = signer.Decode("accounts/bob", value)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestCursorTampered(t *testing.T) {
+	signer, err := NewSigner(util.RandomString(32))
+	assert.NoError(t, err)
+
+	value := signer.Encode("accounts/alice", Cursor{CreatedAt: time.Now(), ID: 1})
+	forged := signer.Encode("accounts/alice", Cursor{CreatedAt: time.Now(), ID: 2})
+	// splice the payload of one cursor onto the signature of another
+	tampered := forged[:21] + value[21:]
+
+	_, err = signer.Decode("accounts/alice", tampered)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	other, err := NewSigner(util.RandomString(32))
+	assert.NoError(t, err)
+
+	_, err = other.Decode("accounts/alice", value)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	for _, invalid := range []string{"", "not base64!", "c2hvcnQ"} {
+		_, err = signer.Decode("accounts/alice", invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
+
+func TestNewSignerKeySize(t *testing.T) {
+	_, err := NewSigner(util.RandomString(31))
+	assert.Error(t, err)
+}
+
+func TestPage(t *testing.T) {
+	cursorOf := func(id int64) Cursor {
+		return Cursor{ID: id}
+	}
+
+	rows, next := Page([]int64{1, 2, 3}, 3, cursorOf)
+	assert.Equal(t, []int64{1, 2, 3}, rows)
+	assert.Nil(t, next)
+
+	rows, next = Page([]int64{1, 2, 3, 4}, 3, cursorOf)
+	assert.Equal(t, []int64{1, 2, 3}, rows)
+	assert.Equal(t, &Cursor{ID: 3}, next)
+}
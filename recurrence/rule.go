@@ -0,0 +1,66 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Supported frequencies of a Rule
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// Rule repeats a date every Interval days, weeks or months from Start.
+// Dates are worked out in the location of Start and keep its time of day
+type Rule struct {
+	Frequency string
+	Interval  int
+	Start     time.Time
+}
+
+// Validate checks that the rule has a known frequency and a positive interval
+func (rule Rule) Validate() error {
+	switch rule.Frequency {
+	case Daily, Weekly, Monthly:
+	default:
+		return fmt.Errorf("unknown frequency %q", rule.Frequency)
+	}
+
+	if rule.Interval < 1 {
+		return errors.New("interval must be at least 1")
+	}
+
+	return nil
+}
+
+// Occurrence returns the nth date of the rule, counting Start as the 0th.
+// Every date is worked out from Start rather than from the one before, so a monthly rule
+// starting on the 31st falls on the last day of shorter months and goes back to the 31st after them
+func (rule Rule) Occurrence(n int) time.Time {
+	switch rule.Frequency {
+	case Daily:
+		return rule.Start.AddDate(0, 0, n*rule.Interval)
+	case Weekly:
+		return rule.Start.AddDate(0, 0, 7*n*rule.Interval)
+	}
+
+	return addMonths(rule.Start, n*rule.Interval)
+}
+
+// addMonths adds months to t, keeping its day unless the month is too short for it
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+
+	// the day before the 1st of the next month is the last day of this one
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+
+	return time.Date(first.Year(), first.Month(), day, hour, min, sec, t.Nanosecond(), t.Location())
+}
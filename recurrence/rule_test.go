@@ -0,0 +1,74 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestOccurrence(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     Rule
+		expected []time.Time
+	}{
+		{
+			name:     "Daily",
+			rule:     Rule{Frequency: Daily, Interval: 1, Start: date(2026, time.February, 27)},
+			expected: []time.Time{date(2026, time.February, 27), date(2026, time.February, 28), date(2026, time.March, 1)},
+		},
+		{
+			name:     "EveryOtherWeek",
+			rule:     Rule{Frequency: Weekly, Interval: 2, Start: date(2026, time.December, 24)},
+			expected: []time.Time{date(2026, time.December, 24), date(2027, time.January, 7), date(2027, time.January, 21)},
+		},
+		{
+			name:     "MonthlyOnThe1st",
+			rule:     Rule{Frequency: Monthly, Interval: 1, Start: date(2026, time.November, 1)},
+			expected: []time.Time{date(2026, time.November, 1), date(2026, time.December, 1), date(2027, time.January, 1)},
+		},
+		{
+			name: "MonthlyAtEndOfMonth",
+			rule: Rule{Frequency: Monthly, Interval: 1, Start: date(2027, time.January, 31)},
+			expected: []time.Time{
+				date(2027, time.January, 31),
+				date(2027, time.February, 28),
+				date(2027, time.March, 31),
+				date(2027, time.April, 30),
+			},
+		},
+		{
+			name:     "LeapYear",
+			rule:     Rule{Frequency: Monthly, Interval: 12, Start: date(2028, time.February, 29)},
+			expected: []time.Time{date(2028, time.February, 29), date(2029, time.February, 28), date(2030, time.February, 28)},
+		},
+		{
+			name:     "Quarterly",
+			rule:     Rule{Frequency: Monthly, Interval: 3, Start: date(2026, time.November, 30)},
+			expected: []time.Time{date(2026, time.November, 30), date(2027, time.February, 28), date(2027, time.May, 30)},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, tc.rule.Validate())
+			for n, expected := range tc.expected {
+				assert.Equal(t, expected, tc.rule.Occurrence(n), n)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := date(2026, time.January, 1)
+
+	assert.Error(t, Rule{Frequency: "yearly", Interval: 1, Start: start}.Validate())
+	assert.Error(t, Rule{Frequency: Daily, Interval: 0, Start: start}.Validate())
+}
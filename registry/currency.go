@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/rs/zerolog/log"
+)
+
+// CurrencyStore is the part of db.Store the currency registry reads from
+type CurrencyStore interface {
+	ListCurrencies(ctx context.Context) ([]db.Currency, error)
+}
+
+// CurrencyRegistry caches the currencies table so validators don't hit the database.
+// Until the first Load it serves the currencies the bank has always supported
+type CurrencyRegistry struct {
+	store      CurrencyStore
+	mutex      sync.RWMutex
+	currencies map[string]db.Currency
+}
+
+// NewCurrencyRegistry creates a new CurrencyRegistry
+func NewCurrencyRegistry(store CurrencyStore) *CurrencyRegistry {
+	currencies := make(map[string]db.Currency)
+	for _, code := range []string{util.CAD, util.EUR, util.GBP, util.USD} {
+		currencies[code] = db.Currency{
+			Code:     code,
+			Exponent: 2,
+			Enabled:  true,
+		}
+	}
+
+	return &CurrencyRegistry{
+		store:      store,
+		currencies: currencies,
+	}
+}
+
+// Load replaces the cached currencies with the content of the currencies table
+func (registry *CurrencyRegistry) Load(ctx context.Context) error {
+	list, err := registry.store.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	currencies := make(map[string]db.Currency, len(list))
+	for _, currency := range list {
+		currencies[currency.Code] = currency
+	}
+
+	registry.mutex.Lock()
+	registry.currencies = currencies
+	registry.mutex.Unlock()
+
+	return nil
+}
+
+// Refresh reloads the currencies every interval until the context is cancelled
+func (registry *CurrencyRegistry) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := registry.Load(ctx); err != nil {
+				log.Error().Err(err).Msg("cannot refresh currency registry")
+			}
+		}
+	}
+}
+
+// Put updates a single cached currency, e.g. after it was enabled or disabled
+func (registry *CurrencyRegistry) Put(currency db.Currency) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.currencies[currency.Code] = currency
+}
+
+// Lookup returns the cached currency whether it is enabled or not
+func (registry *CurrencyRegistry) Lookup(code string) (db.Currency, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// IsSupported returns true if the currency exists and is enabled
+func (registry *CurrencyRegistry) IsSupported(code string) bool {
+	currency, ok := registry.Lookup(code)
+	return ok && currency.Enabled
+}
+
+// Exponent returns the minor units of the cached currency, which lets money
+// parse and format currencies added to the table at runtime
+func (registry *CurrencyRegistry) Exponent(code string) (int, bool) {
+	currency, ok := registry.Lookup(code)
+	return int(currency.Exponent), ok
+}
@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyRegistryDefaults(t *testing.T) {
+	registry := NewCurrencyRegistry(nil)
+
+	for _, code := range []string{util.CAD, util.EUR, util.GBP, util.USD} {
+		assert.True(t, registry.IsSupported(code))
+	}
+	assert.False(t, registry.IsSupported("AUD"))
+}
+
+func TestCurrencyRegistryLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return([]db.Currency{
+			{Code: "AUD", Exponent: 2, Enabled: true, DisplayName: "Australian Dollar"},
+			{Code: util.GBP, Exponent: 2, Enabled: true, DisplayName: "Pound Sterling"},
+			{Code: "JPY", Exponent: 0, Enabled: false, DisplayName: "Yen"},
+		}, nil)
+
+	registry := NewCurrencyRegistry(store)
+	assert.NoError(t, registry.Load(context.Background()))
+
+	assert.True(t, registry.IsSupported("AUD"))
+	assert.True(t, registry.IsSupported(util.GBP))
+	assert.False(t, registry.IsSupported("JPY"))
+	// currencies missing from the table are no longer supported
+	assert.False(t, registry.IsSupported(util.USD))
+
+	currency, ok := registry.Lookup("JPY")
+	assert.True(t, ok)
+	assert.Equal(t, int32(0), currency.Exponent)
+
+	registry.Put(db.Currency{Code: "JPY", Exponent: 0, Enabled: true})
+	assert.True(t, registry.IsSupported("JPY"))
+}
+
+func TestCurrencyRegistryLoadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	registry := NewCurrencyRegistry(store)
+	assert.Error(t, registry.Load(context.Background()))
+
+	// a failed load keeps the cached currencies
+	assert.True(t, registry.IsSupported(util.GBP))
+}
+
+func TestCurrencyRegistryRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loaded := make(chan struct{}, 1)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListCurrencies(gomock.Any()).
+		MinTimes(1).
+		DoAndReturn(func(ctx context.Context) ([]db.Currency, error) {
+			select {
+			case loaded <- struct{}{}:
+			default:
+			}
+			return []db.Currency{{Code: "AUD", Exponent: 2, Enabled: true}}, nil
+		})
+
+	registry := NewCurrencyRegistry(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		registry.Refresh(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	<-loaded
+	cancel()
+	<-done
+
+	assert.True(t, registry.IsSupported("AUD"))
+}
+
+func TestCurrencyRegistryExponent(t *testing.T) {
+	registry := NewCurrencyRegistry(nil)
+	registry.Put(db.Currency{Code: "JPY", Exponent: 0, Enabled: true})
+	registry.Put(db.Currency{Code: "BHD", Exponent: 3, Enabled: false})
+
+	exponent, ok := registry.Exponent("JPY")
+	assert.True(t, ok)
+	assert.Equal(t, 0, exponent)
+
+	exponent, ok = registry.Exponent("BHD")
+	assert.True(t, ok)
+	assert.Equal(t, 3, exponent)
+
+	_, ok = registry.Exponent("PLN")
+	assert.False(t, ok)
+}
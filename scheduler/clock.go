@@ -0,0 +1,17 @@
+package scheduler
+
+import "time"
+
+// Clock tells the workers what time it is, so tests can move time forward instead of waiting
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+// SystemClock is the Clock that reads the system time
+var SystemClock Clock = systemClock{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/money"
+)
+
+// converter converts the amounts of transfers between accounts of different currencies
+type converter struct {
+	rateProvider fx.RateProvider
+}
+
+// convert converts the amount at the current rate, as createTransfer does for an immediate transfer
+func (converter converter) convert(ctx context.Context, amount int64, from string, to string) (int64, money.Rate, error) {
+	rate, err := converter.rateProvider.Rate(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return 0, money.Rate{}, fmt.Errorf("%w: %v", db.ErrCannotConvert, err)
+		}
+		return 0, money.Rate{}, err
+	}
+
+	toAmount, err := money.New(amount, from).Convert(to, rate)
+	if err != nil {
+		return 0, money.Rate{}, fmt.Errorf("%w: %v", db.ErrCannotConvert, err)
+	}
+
+	if !toAmount.IsPositive() {
+		return 0, money.Rate{}, fmt.Errorf("%w: amount is too small to convert from %s to %s", db.ErrCannotConvert, from, to)
+	}
+
+	return toAmount.Minor, rate, nil
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/rs/zerolog/log"
+)
+
+// StandingOrderStore is the part of db.Store the standing order worker uses
+type StandingOrderStore interface {
+	ExecuteStandingOrderTx(ctx context.Context, arg db.ExecuteStandingOrderTxParams) (db.ExecuteStandingOrderTxResult, error)
+}
+
+// StandingOrderWorker pays the occurrences of standing orders as they fall due.
+// Like the transfer scheduler, any number of workers can share a database
+type StandingOrderWorker struct {
+	converter
+	store StandingOrderStore
+	retry db.RetryPolicy
+	clock Clock
+}
+
+// NewStandingOrderWorker creates a new StandingOrderWorker
+func NewStandingOrderWorker(store StandingOrderStore, rateProvider fx.RateProvider, retry db.RetryPolicy, clock Clock) *StandingOrderWorker {
+	return &StandingOrderWorker{
+		converter: converter{rateProvider: rateProvider},
+		store:     store,
+		retry:     retry,
+		clock:     clock,
+	}
+}
+
+// Run pays the due occurrences every interval until the context is cancelled
+func (worker *StandingOrderWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := worker.ExecuteDue(ctx); err != nil {
+				log.Error().Err(err).Msg("cannot execute standing orders")
+			}
+		}
+	}
+}
+
+// ExecuteDue pays occurrences until none are due and returns how many it processed,
+// whether they were paid or failed.
+// An order that cannot be finished is left to its lease and the others are still paid
+func (worker *StandingOrderWorker) ExecuteDue(ctx context.Context) (int, error) {
+	arg := db.ExecuteStandingOrderTxParams{
+		Now:     worker.clock.Now(),
+		Convert: worker.convert,
+		Retry:   worker.retry,
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		result, err := worker.store.ExecuteStandingOrderTx(ctx, arg)
+		order := result.StandingOrder
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				return processed, nil
+			}
+			// nothing was claimed, so the next attempt would fail the same way
+			if order.ID == 0 {
+				return processed, err
+			}
+
+			processed++
+			log.Error().
+				Err(err).
+				Int64("standing_order_id", order.ID).
+				Msg("cannot execute standing order")
+			continue
+		}
+		processed++
+
+		// the order changed while it was claimed, so there was nothing to pay
+		if result.Transfer == nil && !order.LastError.Valid {
+			continue
+		}
+
+		if result.Transfer == nil {
+			log.Warn().
+				Int64("standing_order_id", order.ID).
+				Int32("retry_count", order.RetryCount).
+				Str("reason", order.LastError.String).
+				Msg("standing order occurrence failed")
+			continue
+		}
+
+		log.Info().
+			Int64("standing_order_id", order.ID).
+			Int64("transfer_id", result.Transfer.Transfer.ID).
+			Str("status", order.Status).
+			Msg("paid standing order occurrence")
+	}
+
+	return processed, ctx.Err()
+}
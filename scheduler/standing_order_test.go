@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock only moves when a test moves it
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(d time.Duration) {
+	clock.now = clock.now.Add(d)
+}
+
+func TestStandingOrderExecuteDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+	retry := db.RetryPolicy{MaxRetries: 3, Backoff: time.Hour}
+
+	paid := db.ExecuteStandingOrderTxResult{
+		StandingOrder: db.StandingOrder{
+			ID:          1,
+			Status:      db.StandingOrderActive,
+			Occurrences: 1,
+			NextRunAt:   pgtype.Timestamptz{Time: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+		},
+		Transfer: &db.TransferTxResult{Transfer: db.Transfer{ID: 10}},
+	}
+	failed := db.ExecuteStandingOrderTxResult{
+		StandingOrder: db.StandingOrder{
+			ID:         2,
+			Status:     db.StandingOrderActive,
+			RetryCount: 1,
+			LastError:  pgtype.Text{String: db.ErrInsufficientFunds.Error(), Valid: true},
+		},
+	}
+
+	// the order was cancelled while it was claimed
+	skipped := db.ExecuteStandingOrderTxResult{
+		StandingOrder: db.StandingOrder{ID: 3, Status: db.StandingOrderCancelled},
+	}
+	// the order was claimed but could not be finished, so it waits out its lease
+	broken := db.ExecuteStandingOrderTxResult{
+		StandingOrder: db.StandingOrder{ID: 4, Status: db.StandingOrderActive},
+	}
+
+	var times []time.Time
+	store := mockdb.NewMockStore(ctrl)
+	record := func(ctx context.Context, arg db.ExecuteStandingOrderTxParams) {
+		times = append(times, arg.Now)
+		assert.Equal(t, retry, arg.Retry)
+		assert.NotNil(t, arg.Convert)
+	}
+	gomock.InOrder(
+		store.EXPECT().ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).Do(record).Return(paid, nil),
+		store.EXPECT().ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).Do(record).Return(failed, nil),
+		store.EXPECT().ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).Do(record).Return(skipped, nil),
+		store.EXPECT().ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).Do(record).Return(broken, sql.ErrConnDone),
+		store.EXPECT().
+			ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).
+			Do(record).
+			Return(db.ExecuteStandingOrderTxResult{}, db.ErrRecordNotFound),
+		store.EXPECT().
+			ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).
+			Do(record).
+			Return(db.ExecuteStandingOrderTxResult{}, db.ErrRecordNotFound),
+	)
+
+	worker := NewStandingOrderWorker(store, fx.NewStaticProvider(nil), retry, clock)
+
+	processed, err := worker.ExecuteDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, processed)
+
+	// a month later the worker asks for the orders due by then
+	clock.Advance(31 * 24 * time.Hour)
+	processed, err = worker.ExecuteDue(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, processed)
+
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{start, start, start, start, start, start.Add(31 * 24 * time.Hour)}, times)
+}
+
+func TestStandingOrderExecuteDueError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ExecuteStandingOrderTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ExecuteStandingOrderTxResult{}, sql.ErrConnDone)
+
+	worker := NewStandingOrderWorker(store, fx.NewStaticProvider(nil), db.RetryPolicy{}, SystemClock)
+	processed, err := worker.ExecuteDue(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Zero(t, processed)
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/rs/zerolog/log"
+)
+
+// TransferStore is the part of db.Store the transfer scheduler uses
+type TransferStore interface {
+	ExecuteScheduledTransferTx(ctx context.Context, arg db.ExecuteScheduledTransferTxParams) (db.ExecuteScheduledTransferTxResult, error)
+}
+
+// TransferScheduler executes scheduled transfers once they are due.
+// Any number of schedulers can run against the same database, as each transfer is claimed by one of them
+type TransferScheduler struct {
+	converter
+	store TransferStore
+	retry db.RetryPolicy
+	clock Clock
+}
+
+// NewTransferScheduler creates a new TransferScheduler
+func NewTransferScheduler(store TransferStore, rateProvider fx.RateProvider, retry db.RetryPolicy, clock Clock) *TransferScheduler {
+	return &TransferScheduler{
+		converter: converter{rateProvider: rateProvider},
+		store:     store,
+		retry:     retry,
+		clock:     clock,
+	}
+}
+
+// Run executes the due transfers every interval until the context is cancelled
+func (scheduler *TransferScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := scheduler.ExecuteDue(ctx); err != nil {
+				log.Error().Err(err).Msg("cannot execute scheduled transfers")
+			}
+		}
+	}
+}
+
+// ExecuteDue executes scheduled transfers until none are due and returns how many it processed,
+// whether they were executed, failed or left to be retried.
+// A transfer that cannot be finished is left to its lease and the others are still executed
+func (scheduler *TransferScheduler) ExecuteDue(ctx context.Context) (int, error) {
+	arg := db.ExecuteScheduledTransferTxParams{
+		Now:     scheduler.clock.Now(),
+		Convert: scheduler.convert,
+		Retry:   scheduler.retry,
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		result, err := scheduler.store.ExecuteScheduledTransferTx(ctx, arg)
+		scheduled := result.ScheduledTransfer
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				return processed, nil
+			}
+			// nothing was claimed, so the next attempt would fail the same way
+			if scheduled.ID == 0 {
+				return processed, err
+			}
+
+			processed++
+			log.Error().
+				Err(err).
+				Int64("scheduled_transfer_id", scheduled.ID).
+				Msg("cannot execute scheduled transfer")
+			continue
+		}
+		processed++
+
+		if scheduled.Status == db.ScheduledTransferPending {
+			log.Warn().
+				Int64("scheduled_transfer_id", scheduled.ID).
+				Int32("attempts", scheduled.Attempts).
+				Str("reason", scheduled.LastError.String).
+				Time("next_attempt_at", scheduled.NextAttemptAt).
+				Msg("scheduled transfer will be retried")
+			continue
+		}
+
+		if scheduled.Status == db.ScheduledTransferFailed {
+			log.Warn().
+				Int64("scheduled_transfer_id", scheduled.ID).
+				Str("reason", scheduled.FailureReason.String).
+				Msg("scheduled transfer failed")
+			continue
+		}
+
+		log.Info().
+			Int64("scheduled_transfer_id", scheduled.ID).
+			Int64("transfer_id", scheduled.TransferID.Int64).
+			Msg("executed scheduled transfer")
+	}
+
+	return processed, ctx.Err()
+}
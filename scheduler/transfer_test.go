@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTransferRetry = db.RetryPolicy{MaxRetries: 3, Backoff: time.Minute}
+
+func newTestTransferScheduler(store TransferStore, now time.Time) *TransferScheduler {
+	rateProvider := fx.NewStaticProvider(map[string]money.Rate{"GBP/USD": money.MustParseRate("1.27")})
+	return NewTransferScheduler(store, rateProvider, testTransferRetry, &fakeClock{now: now})
+}
+
+func TestExecuteDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	executed := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{
+			ID:         1,
+			Status:     db.ScheduledTransferExecuted,
+			TransferID: pgtype.Int8{Int64: 10, Valid: true},
+		},
+		Transfer: &db.TransferTxResult{Transfer: db.Transfer{ID: 10}},
+	}
+	failed := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{
+			ID:            2,
+			Status:        db.ScheduledTransferFailed,
+			FailureReason: pgtype.Text{String: db.ErrInsufficientFunds.Error(), Valid: true},
+		},
+	}
+
+	retried := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{
+			ID:            3,
+			Status:        db.ScheduledTransferPending,
+			Attempts:      1,
+			LastError:     pgtype.Text{String: "rate provider unavailable", Valid: true},
+			NextAttemptAt: now.Add(time.Minute),
+		},
+	}
+	// the row was claimed but could not be finished, so it waits out its lease
+	broken := db.ExecuteScheduledTransferTxResult{
+		ScheduledTransfer: db.ScheduledTransfer{ID: 4, Status: db.ScheduledTransferPending},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	checkNow := func(ctx context.Context, arg db.ExecuteScheduledTransferTxParams) {
+		assert.Equal(t, now, arg.Now)
+		assert.Equal(t, testTransferRetry, arg.Retry)
+		assert.NotNil(t, arg.Convert)
+	}
+	gomock.InOrder(
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Do(checkNow).Return(executed, nil),
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Do(checkNow).Return(failed, nil),
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Do(checkNow).Return(retried, nil),
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Do(checkNow).Return(broken, sql.ErrConnDone),
+		store.EXPECT().
+			ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Return(db.ExecuteScheduledTransferTxResult{}, db.ErrRecordNotFound),
+	)
+
+	processed, err := newTestTransferScheduler(store, now).ExecuteDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, processed)
+}
+
+func TestExecuteDueError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrConnDone)
+
+	processed, err := newTestTransferScheduler(store, time.Now()).ExecuteDue(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Zero(t, processed)
+}
+
+func TestExecuteDueCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	processed, err := newTestTransferScheduler(store, time.Now()).ExecuteDue(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, processed)
+}
+
+func TestConvert(t *testing.T) {
+	scheduler := newTestTransferScheduler(nil, time.Now())
+
+	toAmount, rate, err := scheduler.convert(context.Background(), 1000, util.GBP, util.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1270), toAmount)
+	assert.Equal(t, money.MustParseRate("1.27"), rate)
+
+	// a missing rate will not appear by retrying, so the transfer fails
+	_, _, err = scheduler.convert(context.Background(), 1000, util.GBP, util.CAD)
+	assert.ErrorIs(t, err, db.ErrCannotConvert)
+
+	_, _, err = scheduler.convert(context.Background(), 0, util.GBP, util.USD)
+	assert.ErrorIs(t, err, db.ErrCannotConvert)
+}
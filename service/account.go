@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+)
+
+// CreateAccountParams describes a new empty account of the authenticated user
+type CreateAccountParams struct {
+	Owner          string
+	Currency       string
+	IdempotencyKey *db.IdempotencyKeyParams
+}
+
+// CreateAccount opens the account and saves the idempotency key with it
+func (service *Service) CreateAccount(ctx context.Context, arg CreateAccountParams) (db.Account, error) {
+	result, err := service.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Balance:  0,
+			Currency: arg.Currency,
+			Owner:    arg.Owner,
+		},
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+	if err != nil {
+		return db.Account{}, err
+	}
+
+	return result.Account, nil
+}
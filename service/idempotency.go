@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+)
+
+// ErrIdempotencyKeyMismatch is returned when an idempotency key is reused for a different request
+var ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+
+// StoredResponse looks up a live idempotency key of the user to replay its response.
+// It returns false when there is none and the request should be processed
+func (service *Service) StoredResponse(ctx context.Context, arg *db.IdempotencyKeyParams) (db.IdempotencyKey, bool, error) {
+	idempotencyKey, err := service.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.Key,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return idempotencyKey, false, nil
+		}
+		return idempotencyKey, false, err
+	}
+
+	if idempotencyKey.RequestPath != arg.RequestPath || idempotencyKey.RequestHash != arg.RequestHash {
+		return idempotencyKey, false, ErrIdempotencyKeyMismatch
+	}
+
+	return idempotencyKey, true, nil
+}
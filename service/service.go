@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+)
+
+// ErrNotAccountOwner is returned when the authenticated user moves money out of another user's account
+var ErrNotAccountOwner = errors.New("from account doesn't belong to the authenticated user")
+
+// ValidationError is returned when a request is invalid. Field names the request field at fault
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (err *ValidationError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *ValidationError) Unwrap() error {
+	return err.Err
+}
+
+// Service holds the banking logic shared by the HTTP and gRPC servers, so that both behave the same
+type Service struct {
+	store        db.Store
+	rateProvider fx.RateProvider
+}
+
+// New creates a service on top of the store
+func New(store db.Store, rateProvider fx.RateProvider) *Service {
+	return &Service{
+		store:        store,
+		rateProvider: rateProvider,
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/money"
+)
+
+// TransferParams describes a transfer out of an account of the authenticated user
+type TransferParams struct {
+	Username      string
+	FromAccountID int64
+	ToAccountID   int64
+	// Amount is in the currency of the from account
+	Amount money.Amount
+	// CrossCurrency lets the to account hold a different currency, and the amount is converted
+	CrossCurrency bool
+	// ExecuteAt schedules the transfer instead of making it now.
+	// A scheduled cross-currency transfer is converted at the rate of the moment it executes
+	ExecuteAt      time.Time
+	IdempotencyKey *db.IdempotencyKeyParams
+}
+
+// TransferResult is the result of an immediate transfer, or Scheduled is set for a scheduled one
+type TransferResult struct {
+	db.TransferTxResult
+	Scheduled *db.ScheduledTransfer
+}
+
+// Transfer checks the accounts and either moves the money now or saves the transfer for the scheduler.
+// Balances of scheduled transfers are only checked when they execute
+func (service *Service) Transfer(ctx context.Context, arg TransferParams) (TransferResult, error) {
+	scheduled := !arg.ExecuteAt.IsZero()
+	if scheduled && !arg.ExecuteAt.After(time.Now()) {
+		return TransferResult{}, &ValidationError{Field: "execute_at", Err: errors.New("execute_at must be in the future")}
+	}
+
+	fromAccount, err := service.validAccount(ctx, arg.FromAccountID, arg.Amount.Currency)
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	if fromAccount.Owner != arg.Username {
+		return TransferResult{}, ErrNotAccountOwner
+	}
+
+	toCurrency := arg.Amount.Currency
+	if arg.CrossCurrency {
+		toCurrency = ""
+	}
+
+	toAccount, err := service.validAccount(ctx, arg.ToAccountID, toCurrency)
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	if scheduled {
+		transfer, err := service.store.CreateScheduledTransferTx(ctx, db.CreateScheduledTransferTxParams{
+			CreateScheduledTransferParams: db.CreateScheduledTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        arg.Amount.Minor,
+				ExecuteAt:     arg.ExecuteAt,
+			},
+			IdempotencyKey: arg.IdempotencyKey,
+		})
+		if err != nil {
+			return TransferResult{}, err
+		}
+		return TransferResult{Scheduled: &transfer}, nil
+	}
+
+	txArg := db.TransferTxParams{
+		Amount:         arg.Amount.Minor,
+		FromAccountID:  arg.FromAccountID,
+		ToAccountID:    arg.ToAccountID,
+		IdempotencyKey: arg.IdempotencyKey,
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		toAmount, rate, err := service.convert(ctx, arg.Amount, toAccount.Currency)
+		if err != nil {
+			return TransferResult{}, err
+		}
+
+		txArg.ExchangeRate = rate
+		txArg.ToAmount = toAmount.Minor
+	}
+
+	result, err := service.store.TransferTx(ctx, txArg)
+	if err != nil {
+		return TransferResult{}, err
+	}
+
+	return TransferResult{TransferTxResult: result}, nil
+}
+
+// validAccount loads the account and checks its currency. An empty currency accepts any account currency
+func (service *Service) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := service.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, fmt.Errorf("cannot get account [%d]: %w", accountID, err)
+	}
+
+	if currency != "" && account.Currency != currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		return account, &ValidationError{Field: "currency", Err: err}
+	}
+
+	return account, nil
+}
+
+// convert converts the amount into the currency of the to account at the current rate.
+// It returns fx.ErrRateNotFound when there is no rate between the currencies
+func (service *Service) convert(ctx context.Context, amount money.Amount, currency string) (money.Amount, money.Rate, error) {
+	rate, err := service.rateProvider.Rate(ctx, amount.Currency, currency)
+	if err != nil {
+		return money.Amount{}, money.Rate{}, err
+	}
+
+	toAmount, err := amount.Convert(currency, rate)
+	if err != nil {
+		return money.Amount{}, money.Rate{}, &ValidationError{Field: "amount", Err: err}
+	}
+
+	if !toAmount.IsPositive() {
+		err := fmt.Errorf("amount is too small to convert from %s to %s", amount.Currency, currency)
+		return money.Amount{}, money.Rate{}, &ValidationError{Field: "amount", Err: err}
+	}
+
+	return toAmount, rate, nil
+}
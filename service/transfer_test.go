@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/fx"
+	"github.com/drmanalo/simplebank/money"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfer(t *testing.T) {
+	owner := util.RandomOwner()
+	gbp := db.Account{ID: 1, Owner: owner, Currency: util.GBP}
+	usd := db.Account{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+	eur := db.Account{ID: 3, Owner: util.RandomOwner(), Currency: util.EUR}
+
+	testCases := []struct {
+		name string
+		arg  TransferParams
+		// rates defaults to GBP/USD=1.25
+		rates      map[string]money.Rate
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, result TransferResult, err error)
+	}{
+		{
+			name: "CrossCurrency",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP), CrossCurrency: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(usd.ID)).Times(1).Return(usd, nil)
+				arg := db.TransferTxParams{FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: 1000, ToAmount: 1250, ExchangeRate: money.MustParseRate("1.25")}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				require.NoError(t, err)
+				require.Nil(t, result.Scheduled)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(usd.ID)).Times(1).Return(usd, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				require.Equal(t, "currency", validationErr.Field)
+			},
+		},
+		{
+			name: "NotAccountOwner",
+			arg:  TransferParams{Username: usd.Owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				require.ErrorIs(t, err, ErrNotAccountOwner)
+			},
+		},
+		{
+			name: "RateNotFound",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: eur.ID, Amount: money.New(1000, util.GBP), CrossCurrency: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(eur.ID)).Times(1).Return(eur, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				require.ErrorIs(t, err, fx.ErrRateNotFound)
+			},
+		},
+		{
+			name:  "TooSmallToConvert",
+			arg:   TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP), CrossCurrency: true},
+			rates: map[string]money.Rate{"GBP/USD": money.MustParseRate("0.0001")},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(usd.ID)).Times(1).Return(usd, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				require.Equal(t, "amount", validationErr.Field)
+			},
+		},
+		{
+			name: "Scheduled",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP), CrossCurrency: true, ExecuteAt: time.Now().Add(time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(gbp, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(usd.ID)).Times(1).Return(usd, nil)
+				store.EXPECT().CreateScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{ID: 7}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, result.Scheduled)
+				require.Equal(t, int64(7), result.Scheduled.ID)
+			},
+		},
+		{
+			name: "ExecuteAtInPast",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP), ExecuteAt: time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				require.Equal(t, "execute_at", validationErr.Field)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			arg:  TransferParams{Username: owner, FromAccountID: gbp.ID, ToAccountID: usd.ID, Amount: money.New(1000, util.GBP)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(gbp.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			check: func(t *testing.T, result TransferResult, err error) {
+				require.True(t, errors.Is(err, db.ErrRecordNotFound))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			rates := tc.rates
+			if rates == nil {
+				rates = map[string]money.Rate{"GBP/USD": money.MustParseRate("1.25")}
+			}
+
+			service := New(store, fx.NewStaticProvider(rates))
+			result, err := service.Transfer(context.Background(), tc.arg)
+			tc.check(t, result, err)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package token
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Maker is an interface for managing tokens
+type Maker interface {
+	// CreateToken creates a new token for a specific username, session, type and duration
+	CreateToken(username string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (string, *Payload, error)
+
+	// VerifyToken checks if the token is valid and of the expected type
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
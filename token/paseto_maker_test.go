@@ -0,0 +1,89 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aead/chacha20poly1305"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, sessionID, TokenTypeAccessToken, duration)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, payload)
+
+	assert.NotZero(t, payload.ID)
+	assert.Equal(t, TokenTypeAccessToken, payload.Type)
+	assert.Equal(t, sessionID, payload.SessionID)
+	assert.Equal(t, username, payload.Username)
+	assert.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	assert.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), uuid.New(), TokenTypeAccessToken, -time.Minute)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
+	assert.Error(t, err)
+	assert.EqualError(t, err, ErrExpiredToken.Error())
+	assert.Nil(t, payload)
+}
+
+func TestTamperedPasetoToken(t *testing.T) {
+	maker1, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	maker2, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(util.RandomOwner(), uuid.New(), TokenTypeAccessToken, time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token, TokenTypeAccessToken)
+	assert.Error(t, err)
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	assert.Nil(t, payload)
+}
+
+func TestInvalidPasetoKeySize(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(chacha20poly1305.KeySize + 1))
+	assert.Error(t, err)
+	assert.Nil(t, maker)
+}
+
+func TestWrongPasetoTokenType(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), uuid.New(), TokenTypeRefreshToken, time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+	assert.Error(t, err)
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	assert.Nil(t, payload)
+}
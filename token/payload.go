@@ -0,0 +1,99 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Different types of error returned by the VerifyToken function
+var (
+	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// TokenType tells access tokens and refresh tokens apart, as the same maker signs both
+type TokenType byte
+
+const (
+	TokenTypeAccessToken  TokenType = 1
+	TokenTypeRefreshToken TokenType = 2
+)
+
+// Payload contains the payload data of the token.
+// SessionID is the login session the token was issued for, so the token stops working once it is blocked
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Type      TokenType `json:"token_type"`
+	SessionID uuid.UUID `json:"session_id"`
+	Username  string    `json:"username"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// NewPayload creates a new token payload with a specific username, session, type and duration
+func NewPayload(username string, sessionID uuid.UUID, tokenType TokenType, duration time.Duration) (*Payload, error) {
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{
+		ID:        tokenID,
+		Type:      tokenType,
+		SessionID: sessionID,
+		Username:  username,
+		IssuedAt:  time.Now(),
+		ExpiredAt: time.Now().Add(duration),
+	}
+	return payload, nil
+}
+
+// Valid checks if the token payload is valid or not
+func (payload *Payload) Valid() error {
+	if time.Now().After(payload.ExpiredAt) {
+		return ErrExpiredToken
+	}
+	return nil
+}
+
+// VerifyTokenType checks that the token is of the expected type, so a refresh token
+// cannot be used as an access token or the other way round
+func (payload *Payload) VerifyTokenType(tokenType TokenType) error {
+	if payload.Type != tokenType {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// GetExpirationTime implements the jwt.Claims interface
+func (payload *Payload) GetExpirationTime() (*jwt.NumericDate, error) {
+	return jwt.NewNumericDate(payload.ExpiredAt), nil
+}
+
+// GetIssuedAt implements the jwt.Claims interface
+func (payload *Payload) GetIssuedAt() (*jwt.NumericDate, error) {
+	return jwt.NewNumericDate(payload.IssuedAt), nil
+}
+
+// GetNotBefore implements the jwt.Claims interface
+func (payload *Payload) GetNotBefore() (*jwt.NumericDate, error) {
+	return nil, nil
+}
+
+// GetIssuer implements the jwt.Claims interface
+func (payload *Payload) GetIssuer() (string, error) {
+	return "", nil
+}
+
+// GetSubject implements the jwt.Claims interface
+func (payload *Payload) GetSubject() (string, error) {
+	return payload.Username, nil
+}
+
+// GetAudience implements the jwt.Claims interface
+func (payload *Payload) GetAudience() (jwt.ClaimStrings, error) {
+	return nil, nil
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,18 +10,33 @@ import (
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	Environment          string        `mapstructure:"ENVIRONMENT"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	MigrationURL         string        `mapstructure:"MIGRATION_URL"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	EmailSenderName      string        `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress   string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
-	EmailSenderPassword  string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	Environment               string        `mapstructure:"ENVIRONMENT"`
+	DBSource                  string        `mapstructure:"DB_SOURCE"`
+	RedisAddress              string        `mapstructure:"REDIS_ADDRESS"`
+	HTTPServerAddress         string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GatewayServerAddress      string        `mapstructure:"GATEWAY_SERVER_ADDRESS"`
+	GRPCServerAddress         string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	CursorSigningKey          string        `mapstructure:"CURSOR_SIGNING_KEY"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	IdempotencyKeyDuration    time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	EmailSenderName           string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress        string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword       string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	VerifyEmailURL            string        `mapstructure:"VERIFY_EMAIL_URL"`
+	FXProvider                string        `mapstructure:"FX_PROVIDER"`
+	FXRates                   string        `mapstructure:"FX_RATES"`
+	FXRatesFile               string        `mapstructure:"FX_RATES_FILE"`
+	FXRatesURL                string        `mapstructure:"FX_RATES_URL"`
+	CurrencyRefreshInterval   time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	TransferSchedulerInterval time.Duration `mapstructure:"TRANSFER_SCHEDULER_INTERVAL"`
+	TransferMaxRetries        int32         `mapstructure:"SCHEDULED_TRANSFER_MAX_RETRIES"`
+	TransferRetryBackoff      time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_BACKOFF"`
+	StandingOrderInterval     time.Duration `mapstructure:"STANDING_ORDER_INTERVAL"`
+	StandingOrderMaxRetries   int32         `mapstructure:"STANDING_ORDER_MAX_RETRIES"`
+	StandingOrderRetryBackoff time.Duration `mapstructure:"STANDING_ORDER_RETRY_BACKOFF"`
+	MigrationLockTimeout      time.Duration `mapstructure:"MIGRATION_LOCK_TIMEOUT"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -29,11 +45,70 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	// tokens and idempotency keys that expire at once would break every login and retry
+	viper.SetDefault("ACCESS_TOKEN_DURATION", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_DURATION", 24*time.Hour)
+	viper.SetDefault("IDEMPOTENCY_KEY_DURATION", 24*time.Hour)
+	// background loops tick at these intervals, so they must never be left at zero
+	viper.SetDefault("CURRENCY_REFRESH_INTERVAL", time.Minute)
+	viper.SetDefault("TRANSFER_SCHEDULER_INTERVAL", 10*time.Second)
+	viper.SetDefault("STANDING_ORDER_INTERVAL", time.Minute)
+	viper.SetDefault("STANDING_ORDER_MAX_RETRIES", 3)
+	viper.SetDefault("STANDING_ORDER_RETRY_BACKOFF", time.Hour)
+	// a claimed scheduled transfer is leased for one backoff, so it cannot be zero either
+	viper.SetDefault("SCHEDULED_TRANSFER_MAX_RETRIES", 5)
+	viper.SetDefault("SCHEDULED_TRANSFER_RETRY_BACKOFF", time.Minute)
+	// a replica waits this long for another one to finish migrating before giving up
+	viper.SetDefault("MIGRATION_LOCK_TIMEOUT", 5*time.Minute)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.validate()
 	return
 }
+
+// validate rejects values the server cannot start with
+func (config Config) validate() error {
+	durations := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"ACCESS_TOKEN_DURATION", config.AccessTokenDuration},
+		{"REFRESH_TOKEN_DURATION", config.RefreshTokenDuration},
+		{"IDEMPOTENCY_KEY_DURATION", config.IdempotencyKeyDuration},
+		{"CURRENCY_REFRESH_INTERVAL", config.CurrencyRefreshInterval},
+		{"TRANSFER_SCHEDULER_INTERVAL", config.TransferSchedulerInterval},
+		{"SCHEDULED_TRANSFER_RETRY_BACKOFF", config.TransferRetryBackoff},
+		{"STANDING_ORDER_INTERVAL", config.StandingOrderInterval},
+		{"STANDING_ORDER_RETRY_BACKOFF", config.StandingOrderRetryBackoff},
+		{"MIGRATION_LOCK_TIMEOUT", config.MigrationLockTimeout},
+	}
+	for _, d := range durations {
+		if d.duration <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", d.name, d.duration)
+		}
+	}
+
+	retries := []struct {
+		name       string
+		maxRetries int32
+	}{
+		{"SCHEDULED_TRANSFER_MAX_RETRIES", config.TransferMaxRetries},
+		{"STANDING_ORDER_MAX_RETRIES", config.StandingOrderMaxRetries},
+	}
+	for _, r := range retries {
+		if r.maxRetries < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", r.name, r.maxRetries)
+		}
+	}
+
+	return nil
+}
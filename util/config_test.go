@@ -0,0 +1,76 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("ENVIRONMENT=test\n"), 0o600)
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, config.AccessTokenDuration)
+	assert.Equal(t, 24*time.Hour, config.RefreshTokenDuration)
+	assert.Equal(t, 24*time.Hour, config.IdempotencyKeyDuration)
+	assert.Equal(t, time.Minute, config.CurrencyRefreshInterval)
+	assert.Equal(t, 10*time.Second, config.TransferSchedulerInterval)
+	assert.Equal(t, int32(5), config.TransferMaxRetries)
+	assert.Equal(t, time.Minute, config.TransferRetryBackoff)
+	assert.Equal(t, time.Minute, config.StandingOrderInterval)
+	assert.Equal(t, int32(3), config.StandingOrderMaxRetries)
+	assert.Equal(t, time.Hour, config.StandingOrderRetryBackoff)
+	assert.Equal(t, 5*time.Minute, config.MigrationLockTimeout)
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{
+		AccessTokenDuration:       15 * time.Minute,
+		RefreshTokenDuration:      24 * time.Hour,
+		IdempotencyKeyDuration:    24 * time.Hour,
+		CurrencyRefreshInterval:   time.Minute,
+		TransferSchedulerInterval: 10 * time.Second,
+		TransferRetryBackoff:      time.Minute,
+		StandingOrderInterval:     time.Minute,
+		StandingOrderRetryBackoff: time.Hour,
+		MigrationLockTimeout:      time.Minute,
+	}
+	assert.NoError(t, config.validate())
+
+	config.AccessTokenDuration = 0
+	assert.EqualError(t, config.validate(), "ACCESS_TOKEN_DURATION must be positive, got 0s")
+
+	config.AccessTokenDuration = 15 * time.Minute
+	config.RefreshTokenDuration = -time.Hour
+	assert.EqualError(t, config.validate(), "REFRESH_TOKEN_DURATION must be positive, got -1h0m0s")
+
+	config.RefreshTokenDuration = 24 * time.Hour
+	config.IdempotencyKeyDuration = 0
+	assert.EqualError(t, config.validate(), "IDEMPOTENCY_KEY_DURATION must be positive, got 0s")
+
+	config.IdempotencyKeyDuration = 24 * time.Hour
+	config.CurrencyRefreshInterval = -time.Second
+	assert.EqualError(t, config.validate(), "CURRENCY_REFRESH_INTERVAL must be positive, got -1s")
+
+	config.CurrencyRefreshInterval = time.Minute
+	config.TransferSchedulerInterval = -time.Second
+	assert.EqualError(t, config.validate(), "TRANSFER_SCHEDULER_INTERVAL must be positive, got -1s")
+
+	config.TransferSchedulerInterval = 10 * time.Second
+	config.TransferRetryBackoff = 0
+	assert.EqualError(t, config.validate(), "SCHEDULED_TRANSFER_RETRY_BACKOFF must be positive, got 0s")
+
+	config.TransferRetryBackoff = time.Minute
+	config.StandingOrderRetryBackoff = -time.Hour
+	assert.EqualError(t, config.validate(), "STANDING_ORDER_RETRY_BACKOFF must be positive, got -1h0m0s")
+
+	config.StandingOrderRetryBackoff = time.Hour
+	config.StandingOrderMaxRetries = -1
+	assert.EqualError(t, config.validate(), "STANDING_ORDER_MAX_RETRIES must not be negative, got -1")
+}
@@ -0,0 +1,88 @@
+package val
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+)
+
+var (
+	isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+)
+
+// ValidateString checks that the length of value is between minLength and maxLength
+func ValidateString(value string, minLength int, maxLength int) error {
+	n := len(value)
+	if n < minLength || n > maxLength {
+		return fmt.Errorf("must contain from %d-%d characters", minLength, maxLength)
+	}
+	return nil
+}
+
+// ValidateUsername checks that the username is alphanumeric
+func ValidateUsername(value string) error {
+	if err := ValidateString(value, 3, 100); err != nil {
+		return err
+	}
+	if !isValidUsername(value) {
+		return fmt.Errorf("must contain only letters or digits")
+	}
+	return nil
+}
+
+// ValidateFullName checks that the full name only contains letters or spaces
+func ValidateFullName(value string) error {
+	if err := ValidateString(value, 3, 100); err != nil {
+		return err
+	}
+	if !isValidFullName(value) {
+		return fmt.Errorf("must contain only letters or spaces")
+	}
+	return nil
+}
+
+// ValidatePassword checks the length of the password
+func ValidatePassword(value string) error {
+	return ValidateString(value, 6, 100)
+}
+
+// ValidateEmail checks that the email address is well formed
+func ValidateEmail(value string) error {
+	if err := ValidateString(value, 3, 200); err != nil {
+		return err
+	}
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+// CurrencyChecker reports whether a currency is enabled
+type CurrencyChecker interface {
+	IsSupported(code string) bool
+}
+
+// ValidateCurrency checks that the currency is supported
+func ValidateCurrency(value string, currencies CurrencyChecker) error {
+	if !currencies.IsSupported(value) {
+		return fmt.Errorf("is not a supported currency")
+	}
+	return nil
+}
+
+// ValidateID checks that the ID is a positive number
+func ValidateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive number")
+	}
+	return nil
+}
+
+// ValidateIdempotencyKey checks the length of an optional idempotency key
+func ValidateIdempotencyKey(value string) error {
+	if len(value) > 255 {
+		return fmt.Errorf("must not exceed 255 characters")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+)
+
+// TaskDistributor enqueues background tasks to be picked up by a TaskProcessor
+type TaskDistributor interface {
+	DistributeTaskSendVerifyEmail(
+		ctx context.Context,
+		payload *PayloadSendVerifyEmail,
+		opts ...asynq.Option,
+	) error
+}
+
+// RedisTaskDistributor is a TaskDistributor backed by Redis
+type RedisTaskDistributor struct {
+	client *asynq.Client
+}
+
+// NewRedisTaskDistributor creates a new RedisTaskDistributor
+func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
+	client := asynq.NewClient(redisOpt)
+	return &RedisTaskDistributor{
+		client: client,
+	}
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributeTaskSendVerifyEmail(t *testing.T) {
+	redis := miniredis.RunT(t)
+	redisOpt := asynq.RedisClientOpt{Addr: redis.Addr()}
+
+	distributor := NewRedisTaskDistributor(redisOpt)
+
+	payload := &PayloadSendVerifyEmail{Username: util.RandomOwner()}
+	err := distributor.DistributeTaskSendVerifyEmail(context.Background(), payload, asynq.Queue(QueueCritical))
+	assert.NoError(t, err)
+
+	inspector := asynq.NewInspector(redisOpt)
+	defer inspector.Close()
+
+	tasks, err := inspector.ListPendingTasks(QueueCritical)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, TaskSendVerifyEmail, tasks[0].Type)
+
+	var gotPayload PayloadSendVerifyEmail
+	err = json.Unmarshal(tasks[0].Payload, &gotPayload)
+	assert.NoError(t, err)
+	assert.Equal(t, payload.Username, gotPayload.Username)
+}
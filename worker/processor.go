@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/mail"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	QueueCritical = "critical"
+	QueueDefault  = "default"
+)
+
+// TaskProcessor picks up background tasks from the queue and processes them
+type TaskProcessor interface {
+	Start() error
+	Shutdown()
+	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+}
+
+// RedisTaskProcessor is a TaskProcessor backed by Redis
+type RedisTaskProcessor struct {
+	server *asynq.Server
+	config util.Config
+	store  db.Store
+	mailer mail.EmailSender
+}
+
+// NewRedisTaskProcessor creates a new RedisTaskProcessor
+func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, config util.Config, store db.Store, mailer mail.EmailSender) TaskProcessor {
+	server := asynq.NewServer(
+		redisOpt,
+		asynq.Config{
+			Queues: map[string]int{
+				QueueCritical: 10,
+				QueueDefault:  5,
+			},
+			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+				log.Error().Err(err).Str("type", task.Type()).
+					Bytes("payload", task.Payload()).Msg("process task failed")
+			}),
+		},
+	)
+
+	return &RedisTaskProcessor{
+		server: server,
+		config: config,
+		store:  store,
+		mailer: mailer,
+	}
+}
+
+// Start registers the task handlers and starts processing tasks
+func (processor *RedisTaskProcessor) Start() error {
+	mux := asynq.NewServeMux()
+
+	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+
+	return processor.server.Start(mux)
+}
+
+// Shutdown stops processing tasks and waits for the running ones to finish
+func (processor *RedisTaskProcessor) Shutdown() {
+	processor.server.Shutdown()
+}
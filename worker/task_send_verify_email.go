@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendVerifyEmail = "task:send_verify_email"
+
+// PayloadSendVerifyEmail is the payload of the send verify email task
+type PayloadSendVerifyEmail struct {
+	Username string `json:"username"`
+}
+
+var verifyEmailTemplate = template.Must(template.New("verify_email").Parse(`Hello {{.FullName}},<br/>
+Thank you for registering with us!<br/>
+Please <a href="{{.VerifyURL}}">click here</a> to verify your email address.<br/>
+`))
+
+// DistributeTaskSendVerifyEmail enqueues a task to send a verification email to a new user
+func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
+	ctx context.Context,
+	payload *PayloadSendVerifyEmail,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendVerifyEmail, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskSendVerifyEmail creates a verify email record and sends the verification link to the user
+func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendVerifyEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("user doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verify email: %w", err)
+	}
+
+	verifyURL := fmt.Sprintf("%s?id=%d&secret_code=%s",
+		processor.config.VerifyEmailURL, verifyEmail.ID, verifyEmail.SecretCode)
+
+	var content bytes.Buffer
+	err = verifyEmailTemplate.Execute(&content, struct {
+		FullName  string
+		VerifyURL string
+	}{
+		FullName:  user.FullName,
+		VerifyURL: verifyURL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render verify email: %w", err)
+	}
+
+	subject := "Welcome to Simple Bank"
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content.String(), to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send verify email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Msg("processed task")
+	return nil
+}
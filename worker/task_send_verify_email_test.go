@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/drmanalo/simplebank/db/mock"
+	db "github.com/drmanalo/simplebank/db/sqlc"
+	"github.com/drmanalo/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+)
+
+type sentEmail struct {
+	subject string
+	content string
+	to      []string
+}
+
+// fakeEmailSender records the emails instead of sending them over SMTP
+type fakeEmailSender struct {
+	sent []sentEmail
+	err  error
+}
+
+func (sender *fakeEmailSender) SendEmail(
+	subject string,
+	content string,
+	to []string,
+	cc []string,
+	bcc []string,
+	attachFiles []string,
+) error {
+	if sender.err != nil {
+		return sender.err
+	}
+
+	sender.sent = append(sender.sent, sentEmail{subject, content, to})
+	return nil
+}
+
+func TestProcessTaskSendVerifyEmail(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+
+	verifyEmail := db.VerifyEmail{
+		ID:         util.RandomInt(1, 1000),
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: util.RandomString(32),
+	}
+
+	testCases := []struct {
+		name       string
+		payload    []byte
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, sender *fakeEmailSender, err error)
+	}{
+		{
+			name:    "OK",
+			payload: mustMarshal(t, PayloadSendVerifyEmail{Username: user.Username}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(1).Return(verifyEmail, nil)
+			},
+			check: func(t *testing.T, sender *fakeEmailSender, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, sender.sent, 1)
+				assert.Equal(t, []string{user.Email}, sender.sent[0].to)
+				assert.Contains(t, sender.sent[0].content, user.FullName)
+				assert.Contains(t, sender.sent[0].content, verifyEmail.SecretCode)
+			},
+		},
+		{
+			name:    "UserNotFound",
+			payload: mustMarshal(t, PayloadSendVerifyEmail{Username: user.Username}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, sender *fakeEmailSender, err error) {
+				assert.ErrorIs(t, err, asynq.SkipRetry)
+				assert.Empty(t, sender.sent)
+			},
+		},
+		{
+			name:    "CreateVerifyEmailError",
+			payload: mustMarshal(t, PayloadSendVerifyEmail{Username: user.Username}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.VerifyEmail{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, sender *fakeEmailSender, err error) {
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, asynq.SkipRetry)
+				assert.Empty(t, sender.sent)
+			},
+		},
+		{
+			name:    "InvalidPayload",
+			payload: []byte("invalid"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, sender *fakeEmailSender, err error) {
+				assert.ErrorIs(t, err, asynq.SkipRetry)
+				assert.Empty(t, sender.sent)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			sender := &fakeEmailSender{}
+			processor := &RedisTaskProcessor{
+				config: util.Config{VerifyEmailURL: "http://localhost:8080/verify_email"},
+				store:  store,
+				mailer: sender,
+			}
+
+			task := asynq.NewTask(TaskSendVerifyEmail, tc.payload)
+			err := processor.ProcessTaskSendVerifyEmail(context.Background(), task)
+			tc.check(t, sender, err)
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return data
+}